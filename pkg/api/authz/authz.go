@@ -36,6 +36,7 @@ var staticRules = map[string][]string{
 		"/oauth2/",
 
 		"POST /api/webhooks/{namespace}/{id}",
+		"POST /api/workflow-callbacks/{namespace}/{id}/{step}",
 		"GET /api/token-request/{id}",
 		"POST /api/token-request",
 		"GET /api/token-request/{id}/{service}",
@@ -1,18 +1,16 @@
 package handlers
 
 import (
-	"fmt"
 	"net/http"
 	"time"
 
-	"github.com/adhocore/gronx"
 	"github.com/obot-platform/obot/apiclient/types"
 	"github.com/obot-platform/obot/pkg/alias"
 	"github.com/obot-platform/obot/pkg/api"
 	"github.com/obot-platform/obot/pkg/controller/handlers/cronjob"
 	v1 "github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1"
 	"github.com/obot-platform/obot/pkg/system"
-	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"github.com/obot-platform/obot/pkg/workflowparams"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -73,6 +71,12 @@ func (a *CronJobHandler) Update(req api.Context) error {
 		return err
 	}
 
+	if cronJob.Spec.Suspend && !manifest.Suspend {
+		if err := skipSuspendedRuns(req, &cronJob); err != nil {
+			return err
+		}
+	}
+
 	cronJob.Spec.CronJobManifest = *manifest
 	if err = req.Update(&cronJob); err != nil {
 		return err
@@ -104,46 +108,125 @@ func (a *CronJobHandler) ByID(req api.Context) error {
 }
 
 func (a *CronJobHandler) Execute(req api.Context) error {
+	if _, err := startCronJob(req); err != nil {
+		return err
+	}
+
+	req.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// Run starts an execution of the cron job right away and returns it. The run is started even if the cron job is
+// suspended and regardless of its concurrency policy, so that the cron job can be tested.
+func (a *CronJobHandler) Run(req api.Context) error {
+	wfe, err := startCronJob(req)
+	if err != nil {
+		return err
+	}
+
+	return req.WriteCreated(convertWorkflowExecution(*wfe))
+}
+
+func startCronJob(req api.Context) (*v1.WorkflowExecution, error) {
 	var cronJob v1.CronJob
 	if err := req.Get(&cronJob, req.PathValue("id")); err != nil {
-		return err
+		return nil, err
 	}
 
 	var workflow v1.Workflow
 	if err := alias.Get(req.Context(), req.Storage, &workflow, cronJob.Namespace, cronJob.Spec.Workflow); err != nil {
-		return err
+		return nil, err
+	}
+
+	input, err := workflowparams.Validate(workflow.Spec.Manifest.InputParams, cronJob.Spec.Input)
+	if err != nil {
+		return nil, types.NewErrBadRequest("%v", err)
 	}
 
-	if err := req.Create(&v1.WorkflowExecution{
+	wfe := v1.WorkflowExecution{
 		ObjectMeta: metav1.ObjectMeta{
 			GenerateName: system.WorkflowExecutionPrefix,
 			Namespace:    req.Namespace(),
 		},
 		Spec: v1.WorkflowExecutionSpec{
 			WorkflowName: workflow.Name,
-			Input:        cronJob.Spec.Input,
+			Input:        input,
 			CronJobName:  cronJob.Name,
+			ThreadName:   cronJob.Spec.ThreadName,
 		},
-	}); err != nil {
+	}
+	if err := req.Create(&wfe); err != nil {
+		return nil, err
+	}
+
+	return &wfe, nil
+}
+
+func (a *CronJobHandler) Suspend(req api.Context) error {
+	return setCronJobSuspend(req, true)
+}
+
+func (a *CronJobHandler) Resume(req api.Context) error {
+	return setCronJobSuspend(req, false)
+}
+
+func setCronJobSuspend(req api.Context, suspend bool) error {
+	var cronJob v1.CronJob
+	if err := req.Get(&cronJob, req.PathValue("id")); err != nil {
 		return err
 	}
 
-	req.WriteHeader(http.StatusNoContent)
-	return nil
+	if cronJob.Spec.Suspend != suspend {
+		if !suspend {
+			if err := skipSuspendedRuns(req, &cronJob); err != nil {
+				return err
+			}
+		}
+		cronJob.Spec.Suspend = suspend
+		if err := req.Update(&cronJob); err != nil {
+			return err
+		}
+	}
+
+	return req.Write(convertCronJob(cronJob))
+}
+
+// skipSuspendedRuns moves the last scheduled time of a cron job that is about to be resumed to now, so that the
+// times scheduled while it was suspended aren't caught up. The status is updated before the cron job is resumed,
+// because the controller ignores the cron job until then.
+func skipSuspendedRuns(req api.Context, cronJob *v1.CronJob) error {
+	cronJob.Status.LastScheduledTime = &metav1.Time{Time: time.Now()}
+	return req.Storage.Status().Update(req.Context(), cronJob)
 }
 
 func convertCronJob(cronJob v1.CronJob) types.CronJob {
 	var nextRunAt *time.Time
-	if next, err := gronx.NextTick(cronjob.GetSchedule(cronJob), false); err == nil {
+	if next, err := cronjob.NextRun(cronJob, time.Now()); err == nil && !cronJob.Spec.Suspend {
 		nextRunAt = &next
 	}
 
+	nextRuns := make([]types.Time, 0, len(cronJob.Status.NextRuns))
+	for _, next := range cronJob.Status.NextRuns {
+		nextRuns = append(nextRuns, *types.NewTime(next.Time))
+	}
+
+	skippedRuns := make([]types.CronJobSkippedRun, 0, len(cronJob.Status.SkippedRuns))
+	for _, skipped := range cronJob.Status.SkippedRuns {
+		skippedRuns = append(skippedRuns, types.CronJobSkippedRun{
+			ScheduledTime:       *types.NewTime(skipped.ScheduledTime.Time),
+			WorkflowExecutionID: skipped.WorkflowExecutionName,
+			Reason:              skipped.Reason,
+		})
+	}
+
 	return types.CronJob{
 		Metadata:                   MetadataFrom(&cronJob),
 		CronJobManifest:            cronJob.Spec.CronJobManifest,
 		LastRunStartedAt:           v1.NewTime(cronJob.Status.LastRunStartedAt),
 		LastSuccessfulRunCompleted: v1.NewTime(cronJob.Status.LastSuccessfulRunCompleted),
 		NextRunAt:                  types.NewTimeFromPointer(nextRunAt),
+		NextRuns:                   nextRuns,
+		SkippedRuns:                skippedRuns,
 	}
 }
 
@@ -152,8 +235,8 @@ func parseAndValidateCronManifest(req api.Context) (*types.CronJobManifest, erro
 	if err := req.Read(&manifest); err != nil {
 		return nil, err
 	}
-	if !gronx.IsValid(manifest.Schedule) {
-		return nil, apierrors.NewBadRequest(fmt.Sprintf("invalid schedule %s", manifest.Schedule))
+	if err := cronjob.Validate(manifest); err != nil {
+		return nil, types.NewErrBadRequest("%v", err)
 	}
 
 	var workflow v1.Workflow
@@ -161,5 +244,9 @@ func parseAndValidateCronManifest(req api.Context) (*types.CronJobManifest, erro
 		return nil, err
 	}
 
+	if _, err := workflowparams.Validate(workflow.Spec.Manifest.InputParams, manifest.Input); err != nil {
+		return nil, types.NewErrBadRequest("%v", err)
+	}
+
 	return &manifest, nil
 }
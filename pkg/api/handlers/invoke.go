@@ -1,11 +1,16 @@
 package handlers
 
 import (
+	"strconv"
+
+	"github.com/obot-platform/obot/apiclient/types"
 	"github.com/obot-platform/obot/pkg/alias"
 	"github.com/obot-platform/obot/pkg/api"
 	"github.com/obot-platform/obot/pkg/invoke"
 	v1 "github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1"
 	"github.com/obot-platform/obot/pkg/system"
+	"github.com/obot-platform/obot/pkg/workflowparams"
+	"github.com/obot-platform/obot/pkg/workflowvalidate"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 )
 
@@ -94,10 +99,26 @@ func (i *InvokeHandler) Invoke(req api.Context) error {
 		if threadID == "" || stepID != "" {
 			synchronous = false
 		}
+		if threadID == "" {
+			// Only a new execution takes the input of the workflow, otherwise the input is a chat message.
+			validInput, err := workflowparams.Validate(wf.Spec.Manifest.InputParams, string(input))
+			if err != nil {
+				return types.NewErrBadRequest("%v", err)
+			}
+			input = []byte(validInput)
+		}
+		if err := workflowvalidate.RunUntilStep(wf.Spec.Manifest, stepID); err != nil {
+			return types.NewErrBadRequest("%v", err)
+		}
+		budget, err := invokeBudget(req)
+		if err != nil {
+			return err
+		}
 		resp, err = i.invoker.Workflow(req.Context(), req.Storage, &wf, string(input), invoke.WorkflowOptions{
 			Synchronous: synchronous,
 			ThreadName:  threadID,
 			StepID:      stepID,
+			Budget:      budget,
 		})
 		if err != nil {
 			return err
@@ -116,3 +137,29 @@ func (i *InvokeHandler) Invoke(req api.Context) error {
 		"threadID": resp.Thread.Name,
 	})
 }
+
+// invokeBudget returns the budget of a new workflow execution from the maxTokens and maxCost query parameters, which
+// override the limits of the budget of the workflow.
+func invokeBudget(req api.Context) (*types.WorkflowBudget, error) {
+	var (
+		budget types.WorkflowBudget
+		err    error
+	)
+	if maxTokens := req.URL.Query().Get("maxTokens"); maxTokens != "" {
+		if budget.MaxTokens, err = strconv.Atoi(maxTokens); err != nil {
+			return nil, types.NewErrBadRequest("invalid maxTokens %q: %v", maxTokens, err)
+		}
+	}
+	if maxCost := req.URL.Query().Get("maxCost"); maxCost != "" {
+		if budget.MaxCost, err = strconv.ParseFloat(maxCost, 64); err != nil {
+			return nil, types.NewErrBadRequest("invalid maxCost %q: %v", maxCost, err)
+		}
+	}
+	if budget.MaxTokens == 0 && budget.MaxCost == 0 {
+		return nil, nil
+	}
+	if budget.MaxTokens < 0 || budget.MaxCost < 0 {
+		return nil, types.NewErrBadRequest("budget limits must not be negative")
+	}
+	return &budget, nil
+}
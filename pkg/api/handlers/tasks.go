@@ -11,11 +11,13 @@ import (
 	"github.com/obot-platform/nah/pkg/randomtoken"
 	"github.com/obot-platform/obot/apiclient/types"
 	"github.com/obot-platform/obot/pkg/api"
+	"github.com/obot-platform/obot/pkg/controller/handlers/cronjob"
 	"github.com/obot-platform/obot/pkg/events"
 	"github.com/obot-platform/obot/pkg/invoke"
 	v1 "github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1"
 	"github.com/obot-platform/obot/pkg/system"
 	"github.com/obot-platform/obot/pkg/wait"
+	"github.com/obot-platform/obot/pkg/workflowparams"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	kclient "sigs.k8s.io/controller-runtime/pkg/client"
@@ -243,6 +245,12 @@ func (t *TaskHandler) Run(req api.Context) error {
 		return err
 	}
 
+	validInput, err := workflowparams.Validate(workflow.Spec.Manifest.InputParams, string(input))
+	if err != nil {
+		return types.NewErrBadRequest("%v", err)
+	}
+	input = []byte(validInput)
+
 	var wfe *v1.WorkflowExecution
 	if stepID == "" {
 		wfe = &v1.WorkflowExecution{
@@ -478,6 +486,14 @@ func (t *TaskHandler) updateCron(req api.Context, workflow *v1.Workflow, task ty
 		return nil
 	}
 
+	// Task schedules run in the timezone of the user that scheduled them, unless it is unknown.
+	timezone := cron.Spec.Timezone
+	if timezone == "" {
+		if _, err := cronjob.Location(req.UserTimezone()); err == nil {
+			timezone = req.UserTimezone()
+		}
+	}
+
 	if cron.Name == "" {
 		cron = v1.CronJob{
 			ObjectMeta: metav1.ObjectMeta{
@@ -488,6 +504,7 @@ func (t *TaskHandler) updateCron(req api.Context, workflow *v1.Workflow, task ty
 				CronJobManifest: types.CronJobManifest{
 					Workflow:     workflow.Name,
 					TaskSchedule: task.Schedule,
+					Timezone:     timezone,
 				},
 				ThreadName: workflow.Spec.ThreadName,
 			},
@@ -500,8 +517,9 @@ func (t *TaskHandler) updateCron(req api.Context, workflow *v1.Workflow, task ty
 	}
 
 	trigger.CronJob = &cron
-	if cron.Spec.TaskSchedule == nil || *cron.Spec.TaskSchedule != *task.Schedule {
+	if cron.Spec.TaskSchedule == nil || *cron.Spec.TaskSchedule != *task.Schedule || cron.Spec.Timezone != timezone {
 		cron.Spec.TaskSchedule = task.Schedule
+		cron.Spec.Timezone = timezone
 		return req.Update(&cron)
 	}
 
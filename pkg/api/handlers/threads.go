@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"slices"
@@ -10,6 +11,7 @@ import (
 	"github.com/gptscript-ai/go-gptscript"
 	"github.com/obot-platform/obot/apiclient/types"
 	"github.com/obot-platform/obot/pkg/api"
+	"github.com/obot-platform/obot/pkg/controller/handlers/workflowexecution"
 	"github.com/obot-platform/obot/pkg/events"
 	v1 "github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -109,13 +111,31 @@ func convertWorkflowExecution(we v1.WorkflowExecution) types.WorkflowExecution {
 	if we.Status.EndTime != nil {
 		endTime = types.NewTime(we.Status.EndTime.Time)
 	}
+	var structuredOutput json.RawMessage
+	if we.Status.StructuredOutput != "" {
+		structuredOutput = json.RawMessage(we.Status.StructuredOutput)
+	}
+	budget := workflowexecution.Budget(&we)
 	return types.WorkflowExecution{
-		Metadata:  MetadataFrom(&we),
-		Workflow:  w,
-		Input:     we.Spec.Input,
-		Error:     we.Status.Error,
-		StartTime: *types.NewTime(we.CreationTimestamp.Time),
-		EndTime:   endTime,
+		Metadata:         MetadataFrom(&we),
+		Workflow:         w,
+		WorkflowRevision: we.Status.WorkflowRevision,
+		Input:            we.Spec.Input,
+		State:            we.Status.State,
+		Output:           we.Status.Output,
+		StructuredOutput: structuredOutput,
+		Error:            we.Status.Error,
+		CancelledBy:      we.Spec.CancelledBy,
+		DryRun:           we.Spec.DryRun != nil,
+		StartTime:        *types.NewTime(we.CreationTimestamp.Time),
+		EndTime:          endTime,
+		Budget:           budget,
+		Usage: types.WorkflowUsage{
+			PromptTokens:     we.Status.Usage.PromptTokens,
+			CompletionTokens: we.Status.Usage.CompletionTokens,
+			TotalTokens:      we.Status.Usage.TotalTokens,
+			EstimatedCost:    workflowexecution.EstimateCost(budget, we.Status.Usage),
+		},
 	}
 }
 
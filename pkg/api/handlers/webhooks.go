@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"bytes"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
@@ -8,22 +9,37 @@ import (
 	"fmt"
 	"net/http"
 	"net/textproto"
+	"net/url"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/obot-platform/obot/apiclient/types"
 	"github.com/obot-platform/obot/pkg/alias"
 	"github.com/obot-platform/obot/pkg/api"
+	webhookhandler "github.com/obot-platform/obot/pkg/controller/handlers/webhook"
+	"github.com/obot-platform/obot/pkg/hash"
 	v1 "github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1"
 	"github.com/obot-platform/obot/pkg/system"
+	"github.com/obot-platform/obot/pkg/webhooksignature"
+	"github.com/obot-platform/obot/pkg/workflowparams"
 	"golang.org/x/crypto/bcrypt"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/jsonpath"
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 const (
 	WebhookTokenHTTPHeader = "X-Obot-Webhook-Token"
 	WebhookTokenQueryParam = "token"
+	// WebhookWorkflowExecutionHTTPHeader is the execution that a delivery started, or that the original delivery
+	// started for a duplicate.
+	WebhookWorkflowExecutionHTTPHeader = "X-Obot-Workflow-Execution-Id"
+
+	// maxDeliveryAttempts limits how often a delivery looks for an earlier delivery with the same key again, after
+	// a delivery with the same key arrived at the same time.
+	maxDeliveryAttempts = 5
 )
 
 type WebhookHandler struct{}
@@ -52,7 +68,7 @@ func (a *WebhookHandler) Update(req api.Context) error {
 		return err
 	}
 
-	if webhookReq.WebhookManifest.ValidationHeader != "" && webhookReq.WebhookManifest.Secret == "" {
+	if (webhookReq.WebhookManifest.ValidationHeader != "" || webhookReq.WebhookManifest.SignatureScheme != "") && webhookReq.WebhookManifest.Secret == "" {
 		webhookReq.WebhookManifest.Secret = wh.Spec.Secret
 	}
 
@@ -215,7 +231,17 @@ func (a *WebhookHandler) Execute(req api.Context) error {
 		return fmt.Errorf("failed to read request body: %w", err)
 	}
 
-	if webhook.Spec.ValidationHeader != "" {
+	if webhook.Spec.SignatureScheme != "" {
+		if err = webhooksignature.Verify(webhook.Spec.SignatureScheme, webhook.Spec.Signature, webhook.Spec.Secret, webhooksignature.Request{
+			Header: req.Request.Header,
+			URL:    requestURL(req),
+			Body:   body,
+		}, time.Now()); err != nil {
+			log.Debugf("Rejecting request to webhook %s/%s: %v", webhook.Namespace, webhook.Name, err)
+			req.WriteHeader(http.StatusForbidden)
+			return nil
+		}
+	} else if webhook.Spec.ValidationHeader != "" {
 		if err = validateSecretHeader(webhook.Spec.Secret, body, req.Request.Header.Values(webhook.Spec.ValidationHeader)); err != nil {
 			req.WriteHeader(http.StatusForbidden)
 			return nil
@@ -234,6 +260,17 @@ func (a *WebhookHandler) Execute(req api.Context) error {
 		}
 	}
 
+	var workflow v1.Workflow
+	if err := alias.Get(req.Context(), req.Storage, &workflow, req.Namespace(), webhook.Spec.WebhookManifest.Workflow); err != nil {
+		return err
+	}
+
+	// The params of the workflow describe the payload of the webhook.
+	payload, err := workflowparams.Validate(workflow.Spec.Manifest.InputParams, string(body))
+	if err != nil {
+		return types.NewErrBadRequest("%v", err)
+	}
+
 	var input struct {
 		Type    string            `json:"type"`
 		Payload string            `json:"payload"`
@@ -241,7 +278,7 @@ func (a *WebhookHandler) Execute(req api.Context) error {
 	}
 
 	input.Type = "webhook"
-	input.Payload = string(body)
+	input.Payload = payload
 	input.Headers = make(map[string]string)
 
 	allHeaders := slices.Contains(webhook.Spec.Headers, "*")
@@ -258,12 +295,7 @@ func (a *WebhookHandler) Execute(req api.Context) error {
 		return fmt.Errorf("failed to marshal input: %w", err)
 	}
 
-	var workflow v1.Workflow
-	if err := alias.Get(req.Context(), req.Storage, &workflow, req.Namespace(), webhook.Spec.WebhookManifest.Workflow); err != nil {
-		return err
-	}
-
-	if err = req.Create(&v1.WorkflowExecution{
+	wfe := v1.WorkflowExecution{
 		ObjectMeta: metav1.ObjectMeta{
 			GenerateName: system.WorkflowExecutionPrefix,
 			Namespace:    req.Namespace(),
@@ -274,14 +306,162 @@ func (a *WebhookHandler) Execute(req api.Context) error {
 			ThreadName:   webhook.Spec.ThreadName,
 			Input:        string(inputText),
 		},
-	}); err != nil && !apierrors.IsAlreadyExists(err) {
-		return err
 	}
 
+	key, err := deliveryKey(webhook.Spec.Idempotency, req.Request.Header, body)
+	if err != nil {
+		// Deliveries without a key can't be deduplicated, but they are still valid.
+		log.Debugf("No idempotency key for delivery to webhook %s/%s: %v", webhook.Namespace, webhook.Name, err)
+	}
+
+	if key == "" {
+		if err = req.Create(&wfe); err != nil && !apierrors.IsAlreadyExists(err) {
+			return err
+		}
+	} else {
+		window, err := webhookhandler.IdempotencyWindow(webhook.Spec.Idempotency)
+		if err != nil {
+			return err
+		}
+
+		original, err := createDelivery(req, &wfe, webhook, key, window)
+		if err != nil {
+			return err
+		}
+		if original != nil {
+			req.ResponseWriter.Header().Set(WebhookWorkflowExecutionHTTPHeader, original.Name)
+			return req.Write(webhookDelivery{
+				WorkflowExecutionID: original.Name,
+				Duplicate:           true,
+			})
+		}
+	}
+
+	if wfe.Name != "" {
+		req.ResponseWriter.Header().Set(WebhookWorkflowExecutionHTTPHeader, wfe.Name)
+	}
 	req.WriteHeader(http.StatusNoContent)
 	return nil
 }
 
+type webhookDelivery struct {
+	WorkflowExecutionID string `json:"workflowExecutionID"`
+	Duplicate           bool   `json:"duplicate"`
+}
+
+// createDelivery creates the execution for a delivery of a webhook, unless a delivery with the same key already
+// started one within the window, in which case that execution is returned instead. The name of the execution is
+// derived from the key and the latest earlier delivery with the key, so that retries that arrive at the same time
+// can't both create one.
+func createDelivery(req api.Context, wfe *v1.WorkflowExecution, webhook v1.Webhook, key string, window time.Duration) (*v1.WorkflowExecution, error) {
+	wfe.GenerateName = ""
+	wfe.Spec.WebhookDeliveryKey = hash.String([]string{webhook.Namespace, webhook.Name, key})
+
+	for range maxDeliveryAttempts {
+		var deliveries v1.WorkflowExecutionList
+		if err := req.List(&deliveries, kclient.MatchingFields{"spec.webhookDeliveryKey": wfe.Spec.WebhookDeliveryKey}); err != nil {
+			return nil, err
+		}
+
+		var latest *v1.WorkflowExecution
+		for i, delivery := range deliveries.Items {
+			if latest == nil || delivery.CreationTimestamp.After(latest.CreationTimestamp.Time) {
+				latest = &deliveries.Items[i]
+			}
+		}
+
+		var previous string
+		if latest != nil {
+			if time.Since(latest.CreationTimestamp.Time) < window {
+				return latest, nil
+			}
+			previous = latest.Name
+		}
+
+		wfe.Name = system.WorkflowExecutionPrefix + hash.String([]string{wfe.Spec.WebhookDeliveryKey, previous})[:16]
+		if err := req.Create(wfe); err == nil {
+			return nil, nil
+		} else if !apierrors.IsAlreadyExists(err) {
+			return nil, err
+		}
+		// Another delivery with the same key created the execution first, which is found when looking again.
+	}
+
+	return nil, types.NewErrHttp(http.StatusConflict, "too many deliveries with the same idempotency key at once")
+}
+
+// deliveryKey returns the idempotency key of a delivery to a webhook, taken from a header or from the JSON body. An
+// empty key means that the delivery isn't deduplicated.
+func deliveryKey(idempotency *types.WebhookIdempotency, header http.Header, body []byte) (string, error) {
+	switch {
+	case idempotency == nil:
+		return "", nil
+	case idempotency.Header != "":
+		return strings.TrimSpace(header.Get(idempotency.Header)), nil
+	}
+
+	jp, err := parseJSONPath(idempotency.JSONPath)
+	if err != nil {
+		return "", err
+	}
+
+	// Numbers are kept as written, so that large IDs aren't rounded.
+	var data any
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&data); err != nil {
+		return "", fmt.Errorf("body is not JSON: %w", err)
+	}
+
+	var key bytes.Buffer
+	if err := jp.Execute(&key, data); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(key.String()), nil
+}
+
+// parseJSONPath parses a JSONPath into the body of a delivery, which can be written as $.a.b, .a.b or {.a.b}.
+func parseJSONPath(path string) (*jsonpath.JSONPath, error) {
+	expr := strings.TrimSpace(path)
+	if !strings.HasPrefix(expr, "{") {
+		expr = "{" + strings.TrimPrefix(expr, "$") + "}"
+	}
+
+	jp := jsonpath.New("idempotency").AllowMissingKeys(true)
+	if err := jp.Parse(expr); err != nil {
+		return nil, fmt.Errorf("invalid idempotency jsonPath %q: %w", path, err)
+	}
+	return jp, nil
+}
+
+func validateIdempotency(idempotency *types.WebhookIdempotency) error {
+	if idempotency == nil {
+		return nil
+	}
+	if (idempotency.Header != "") == (idempotency.JSONPath != "") {
+		return fmt.Errorf("idempotency must set exactly one of header and jsonPath")
+	}
+	if idempotency.JSONPath != "" {
+		if _, err := parseJSONPath(idempotency.JSONPath); err != nil {
+			return err
+		}
+	}
+	_, err := webhookhandler.IdempotencyWindow(idempotency)
+	return err
+}
+
+// requestURL returns the URL of the request as the sender called it, on the public address of the server.
+func requestURL(req api.Context) string {
+	if base, err := url.Parse(req.APIBaseURL); err == nil && base.Host != "" {
+		return base.Scheme + "://" + base.Host + req.Request.URL.RequestURI()
+	}
+	scheme := "http"
+	if req.Request.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + req.Request.Host + req.Request.URL.RequestURI()
+}
+
 func validateSecretHeader(secret string, body []byte, values []string) error {
 	h := hmac.New(sha256.New, []byte(secret))
 	for _, v := range values {
@@ -317,6 +497,23 @@ func validateManifest(req api.Context, manifest types.WebhookManifest) error {
 		}
 	}
 
+	if err := validateIdempotency(manifest.Idempotency); err != nil {
+		return apierrors.NewBadRequest(err.Error())
+	}
+
+	if manifest.SignatureScheme != "" {
+		if err := webhooksignature.Validate(manifest.SignatureScheme, manifest.Signature); err != nil {
+			return apierrors.NewBadRequest(err.Error())
+		}
+		if manifest.Secret == "" {
+			return apierrors.NewBadRequest("webhook with a signature scheme must have a secret")
+		}
+		if manifest.ValidationHeader != "" {
+			return apierrors.NewBadRequest("webhook with a signature scheme must not set a validation header, the scheme determines the header")
+		}
+		return nil
+	}
+
 	// On creation, the user must set both the validation header and secret or set neither.
 	if (manifest.ValidationHeader != "") != (manifest.Secret != "") {
 		return apierrors.NewBadRequest("webhook must have secret and header set together")
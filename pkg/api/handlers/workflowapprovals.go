@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+
+	"github.com/obot-platform/obot/apiclient/types"
+	"github.com/obot-platform/obot/pkg/api"
+	v1 "github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func (a *WorkflowHandler) ListApprovals(req api.Context) error {
+	steps, err := a.approvalSteps(req)
+	if err != nil {
+		return err
+	}
+
+	var resp types.WorkflowApprovalList
+	for _, step := range steps {
+		approval, err := convertApproval(req, step)
+		if err != nil {
+			return err
+		}
+		resp.Items = append(resp.Items, approval)
+	}
+
+	return req.Write(resp)
+}
+
+func (a *WorkflowHandler) Approve(req api.Context) error {
+	return a.decideApproval(req, v1.ApprovalDecisionApproved)
+}
+
+func (a *WorkflowHandler) Reject(req api.Context) error {
+	return a.decideApproval(req, v1.ApprovalDecisionRejected)
+}
+
+func (a *WorkflowHandler) decideApproval(req api.Context, decision string) error {
+	var input types.WorkflowApprovalDecision
+	if err := req.Read(&input); err != nil && !errors.Is(err, io.EOF) {
+		return types.NewErrBadRequest("invalid approval decision: %v", err)
+	}
+
+	steps, err := a.approvalSteps(req)
+	if err != nil {
+		return err
+	}
+
+	var waiting []v1.WorkflowStep
+	for _, step := range steps {
+		if step.Status.State != types.WorkflowStateWaitingApproval {
+			continue
+		}
+		if input.StepID != "" && step.Spec.Step.ID != input.StepID {
+			continue
+		}
+		waiting = append(waiting, step)
+	}
+
+	switch {
+	case len(waiting) == 0 && input.StepID != "":
+		return types.NewErrNotFound("step %s of execution %s is not waiting for approval", input.StepID, req.PathValue("execution_id"))
+	case len(waiting) == 0:
+		return types.NewErrNotFound("execution %s is not waiting for approval", req.PathValue("execution_id"))
+	case len(waiting) > 1:
+		return types.NewErrBadRequest("execution %s has %d steps waiting for approval, the step ID must be specified", req.PathValue("execution_id"), len(waiting))
+	}
+
+	var (
+		step = waiting[0]
+		now  = metav1.Now()
+	)
+	step.Status.Approval.Decision = decision
+	step.Status.Approval.DecidedBy = req.User.GetName()
+	step.Status.Approval.DecidedAt = &now
+	step.Status.Approval.Comment = input.Comment
+	if err := req.Storage.Status().Update(req.Context(), &step); err != nil {
+		return err
+	}
+
+	approval, err := convertApproval(req, step)
+	if err != nil {
+		return err
+	}
+
+	return req.Write(approval)
+}
+
+// approvalSteps returns the approval steps of the execution that have requested an approval.
+func (a *WorkflowHandler) approvalSteps(req api.Context) ([]v1.WorkflowStep, error) {
+	var (
+		id          = req.PathValue("id")
+		executionID = req.PathValue("execution_id")
+		wfe         v1.WorkflowExecution
+	)
+
+	if err := req.Get(&wfe, executionID); err != nil {
+		return nil, err
+	}
+	if wfe.Spec.WorkflowName != id {
+		return nil, types.NewErrNotFound("workflow execution %s not found for workflow %s", executionID, id)
+	}
+
+	var steps v1.WorkflowStepList
+	if err := req.List(&steps, kclient.MatchingFields{
+		"spec.workflowExecutionName": wfe.Name,
+	}); err != nil {
+		return nil, err
+	}
+
+	var result []v1.WorkflowStep
+	for _, step := range steps.Items {
+		if step.Spec.Step.Approval != nil && step.Status.Approval != nil && step.Spec.WorkflowGeneration == wfe.Spec.WorkflowGeneration {
+			result = append(result, step)
+		}
+	}
+
+	return result, nil
+}
+
+func convertApproval(req api.Context, step v1.WorkflowStep) (types.WorkflowApproval, error) {
+	approval := types.WorkflowApproval{
+		StepID:      step.Spec.Step.ID,
+		Message:     step.Spec.Step.Approval.Message,
+		State:       step.Status.State,
+		RequestedAt: *types.NewTime(step.Status.Approval.RequestedAt.Time),
+		Decision:    step.Status.Approval.Decision,
+		DecidedBy:   step.Status.Approval.DecidedBy,
+		Comment:     step.Status.Approval.Comment,
+	}
+	if step.Status.Approval.DecidedAt != nil {
+		approval.DecidedAt = types.NewTime(step.Status.Approval.DecidedAt.Time)
+	}
+
+	// Include the output of the previous step, which is what is being approved.
+	if step.Spec.AfterWorkflowStepName != "" {
+		var previousStep v1.WorkflowStep
+		if err := req.Get(&previousStep, step.Spec.AfterWorkflowStepName); err != nil {
+			return approval, kclient.IgnoreNotFound(err)
+		}
+		if previousStep.Status.LastRunName != "" {
+			var run v1.Run
+			if err := req.Get(&run, previousStep.Status.LastRunName); err != nil {
+				return approval, kclient.IgnoreNotFound(err)
+			}
+			approval.Output = run.Status.Output
+		}
+	}
+
+	return approval, nil
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/obot-platform/nah/pkg/router"
+	"github.com/obot-platform/obot/apiclient/types"
+	"github.com/obot-platform/obot/pkg/api"
+	"github.com/obot-platform/obot/pkg/invoke"
+	v1 "github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	WorkflowCallbackTokenHTTPHeader = "X-Obot-Callback-Token"
+	WorkflowCallbackTokenQueryParam = "token"
+)
+
+// Callback receives the event that a waitForEvent step of an execution waits for. The request is authenticated with
+// the callback token of the execution and, if the step has a secret, the HMAC signature of the body. The body becomes
+// the output of the step.
+func (a *WorkflowExecutionHandler) Callback(req api.Context) error {
+	var (
+		namespace = req.PathValue("namespace")
+		stepID    = req.PathValue("step")
+		wfe       v1.WorkflowExecution
+	)
+
+	if err := req.Storage.Get(req.Context(), router.Key(namespace, req.PathValue("id")), &wfe); err != nil {
+		return err
+	}
+
+	token := req.Request.Header.Get(WorkflowCallbackTokenHTTPHeader)
+	if token == "" {
+		token = req.Request.URL.Query().Get(WorkflowCallbackTokenQueryParam)
+	}
+	if wfe.Status.CallbackToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(wfe.Status.CallbackToken)) != 1 {
+		req.WriteHeader(http.StatusForbidden)
+		return nil
+	}
+
+	if wfe.Status.WorkflowManifest == nil {
+		return types.NewErrNotFound("step %s of execution %s is not waiting for an event", stepID, wfe.Name)
+	}
+	// The step ID of a wait in a loop or onError block has a suffix, the step of the manifest has the ID without it.
+	manifestStepID, _, _ := strings.Cut(stepID, "{")
+	step, _ := types.FindStep(wfe.Status.WorkflowManifest, manifestStepID)
+	if step == nil || step.WaitForEvent == nil {
+		return types.NewErrNotFound("step %s of execution %s is not waiting for an event", stepID, wfe.Name)
+	}
+
+	body, err := req.Body()
+	if err != nil {
+		return fmt.Errorf("failed to read request body: %w", err)
+	}
+
+	if step.WaitForEvent.ValidationHeader != "" {
+		secret, err := invoke.InterpolateInput(&wfe, step.WaitForEvent.Secret)
+		if err != nil {
+			return err
+		}
+		if err := validateSecretHeader(secret, body, req.Request.Header.Values(step.WaitForEvent.ValidationHeader)); err != nil {
+			req.WriteHeader(http.StatusForbidden)
+			return nil
+		}
+	}
+
+	err = retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		if err := req.Storage.Get(req.Context(), router.Key(wfe.Namespace, wfe.Name), &wfe); err != nil {
+			return err
+		}
+		if wfe.Status.State.IsTerminal() {
+			return types.NewErrHttp(http.StatusConflict, fmt.Sprintf("workflow execution %s is already %s", wfe.Name, wfe.Status.State))
+		}
+		if _, ok := wfe.Status.Callbacks[stepID]; ok {
+			return types.NewErrHttp(http.StatusConflict, fmt.Sprintf("step %s of execution %s already received its event", stepID, wfe.Name))
+		}
+		if wfe.Status.Callbacks == nil {
+			wfe.Status.Callbacks = map[string]v1.WorkflowCallback{}
+		}
+		wfe.Status.Callbacks[stepID] = v1.WorkflowCallback{
+			Payload:    string(body),
+			ReceivedAt: metav1.Now(),
+		}
+		return req.Storage.Status().Update(req.Context(), &wfe)
+	})
+	if err != nil {
+		return err
+	}
+
+	req.WriteHeader(http.StatusNoContent)
+	return nil
+}
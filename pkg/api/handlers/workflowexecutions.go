@@ -0,0 +1,225 @@
+package handlers
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/obot-platform/obot/apiclient/types"
+	"github.com/obot-platform/obot/pkg/api"
+	v1 "github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1"
+	"github.com/obot-platform/obot/pkg/workflowgraph"
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type WorkflowExecutionHandler struct{}
+
+func NewWorkflowExecutionHandler() *WorkflowExecutionHandler {
+	return &WorkflowExecutionHandler{}
+}
+
+func (a *WorkflowExecutionHandler) ByID(req api.Context) error {
+	var wfe v1.WorkflowExecution
+	if err := req.Get(&wfe, req.PathValue("id")); err != nil {
+		return err
+	}
+
+	return req.Write(convertWorkflowExecution(wfe))
+}
+
+func (a *WorkflowExecutionHandler) Cancel(req api.Context) error {
+	var (
+		id  = req.PathValue("id")
+		wfe v1.WorkflowExecution
+	)
+
+	if err := req.Get(&wfe, id); err != nil {
+		return err
+	}
+
+	if !wfe.Spec.Cancel {
+		if wfe.Status.State.IsTerminal() {
+			return types.NewErrBadRequest("workflow execution %s is already %s", id, wfe.Status.State)
+		}
+
+		wfe.Spec.Cancel = true
+		wfe.Spec.CancelledBy = req.User.GetName()
+		if err := req.Update(&wfe); err != nil {
+			return err
+		}
+	}
+
+	return req.Write(convertWorkflowExecution(wfe))
+}
+
+// Steps returns the steps that ran in the current generation of the execution and its subflows, in the order they
+// were started.
+func (a *WorkflowExecutionHandler) Steps(req api.Context) error {
+	var wfe v1.WorkflowExecution
+	if err := req.Get(&wfe, req.PathValue("id")); err != nil {
+		return err
+	}
+
+	steps, err := listExecutionSteps(req, wfe)
+	if err != nil {
+		return err
+	}
+
+	slices.SortStableFunc(steps, func(a, b v1.WorkflowStep) int {
+		return a.CreationTimestamp.Compare(b.CreationTimestamp.Time)
+	})
+
+	resp := types.WorkflowExecutionStepList{Items: make([]types.WorkflowExecutionStep, 0, len(steps))}
+	for _, step := range steps {
+		var output string
+		if step.Status.LastRunName != "" {
+			var run v1.Run
+			if err := req.Get(&run, step.Status.LastRunName); kclient.IgnoreNotFound(err) != nil {
+				return err
+			}
+			output = run.Status.Output
+		}
+
+		var (
+			waitUntil     *types.Time
+			waitRemaining string
+		)
+		if step.Status.Wait != nil {
+			waitUntil = types.NewTime(step.Status.Wait.Until.Time)
+			if remaining := time.Until(step.Status.Wait.Until.Time); remaining > 0 && step.Status.State == types.WorkflowStateWaiting {
+				waitRemaining = remaining.Round(time.Second).String()
+			}
+		}
+
+		resp.Items = append(resp.Items, types.WorkflowExecutionStep{
+			Metadata:            MetadataFrom(&step),
+			WorkflowExecutionID: step.Spec.WorkflowExecutionName,
+			StepID:              step.Spec.Step.ID,
+			State:               step.Status.State,
+			Output:              output,
+			Error:               step.Status.Error,
+			WaitUntil:           waitUntil,
+			WaitRemaining:       waitRemaining,
+		})
+	}
+
+	return req.Write(resp)
+}
+
+func listExecutionSteps(req api.Context, wfe v1.WorkflowExecution) ([]v1.WorkflowStep, error) {
+	result, err := listGenerationSteps(req, wfe)
+	if err != nil {
+		return nil, err
+	}
+
+	subflows, err := listSubflows(req, wfe)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, subflow := range subflows {
+		subflowSteps, err := listExecutionSteps(req, subflow)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, subflowSteps...)
+	}
+
+	return result, nil
+}
+
+// listGenerationSteps returns the steps of the execution itself that ran in its current generation.
+func listGenerationSteps(req api.Context, wfe v1.WorkflowExecution) ([]v1.WorkflowStep, error) {
+	var steps v1.WorkflowStepList
+	if err := req.List(&steps, kclient.MatchingFields{
+		"spec.workflowExecutionName": wfe.Name,
+	}); err != nil {
+		return nil, err
+	}
+
+	var result []v1.WorkflowStep
+	for _, step := range steps.Items {
+		if step.Spec.WorkflowGeneration == wfe.Spec.WorkflowGeneration {
+			result = append(result, step)
+		}
+	}
+
+	return result, nil
+}
+
+func listSubflows(req api.Context, wfe v1.WorkflowExecution) ([]v1.WorkflowExecution, error) {
+	if wfe.Status.ThreadName == "" {
+		return nil, nil
+	}
+
+	var subflows v1.WorkflowExecutionList
+	if err := req.List(&subflows, kclient.MatchingFields{
+		"spec.parentThreadName": wfe.Status.ThreadName,
+	}); err != nil {
+		return nil, err
+	}
+
+	return subflows.Items, nil
+}
+
+// Graph renders the steps that ran in the execution and its subflows as a Mermaid or Graphviz DOT graph.
+func (a *WorkflowExecutionHandler) Graph(req api.Context) error {
+	var wfe v1.WorkflowExecution
+	if err := req.Get(&wfe, req.PathValue("id")); err != nil {
+		return err
+	}
+
+	exec, err := loadGraphExecution(req, wfe)
+	if err != nil {
+		return err
+	}
+
+	graph, err := workflowgraph.FromExecution(exec, func(runName string) string {
+		return req.APIBaseURL + "/runs/" + runName
+	}).Render(req.URL.Query().Get("format"))
+	if err != nil {
+		return types.NewErrBadRequest("%v", err)
+	}
+
+	return req.Write(graph)
+}
+
+func loadGraphExecution(req api.Context, wfe v1.WorkflowExecution) (workflowgraph.Execution, error) {
+	steps, err := listGenerationSteps(req, wfe)
+	if err != nil {
+		return workflowgraph.Execution{}, err
+	}
+
+	exec := workflowgraph.Execution{
+		Execution: wfe,
+		Steps:     steps,
+		Outputs:   map[string]string{},
+	}
+
+	// Only the output of conditions is shown in the graph, so don't fetch the runs of every step.
+	for _, step := range steps {
+		if !strings.Contains(step.Spec.Step.ID, "{condition") || step.Status.LastRunName == "" {
+			continue
+		}
+		var run v1.Run
+		if err := req.Get(&run, step.Status.LastRunName); kclient.IgnoreNotFound(err) != nil {
+			return workflowgraph.Execution{}, err
+		}
+		exec.Outputs[step.Status.LastRunName] = run.Status.Output
+	}
+
+	subflows, err := listSubflows(req, wfe)
+	if err != nil {
+		return workflowgraph.Execution{}, err
+	}
+
+	for _, subflow := range subflows {
+		subflowExec, err := loadGraphExecution(req, subflow)
+		if err != nil {
+			return workflowgraph.Execution{}, err
+		}
+		exec.Subflows = append(exec.Subflows, subflowExec)
+	}
+
+	return exec, nil
+}
@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/obot-platform/obot/apiclient/types"
+	"github.com/obot-platform/obot/pkg/api"
+	"github.com/obot-platform/obot/pkg/controller/handlers/workflow"
+	v1 "github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1"
+	"github.com/sergi/go-diff/diffmatchpatch"
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+func (a *WorkflowHandler) ListRevisions(req api.Context) error {
+	var (
+		id = req.PathValue("id")
+		wf v1.Workflow
+	)
+
+	if err := req.Get(&wf, id); err != nil {
+		return err
+	}
+
+	var revisions v1.WorkflowRevisionList
+	if err := req.List(&revisions, kclient.MatchingFields{
+		"spec.workflowName": wf.Name,
+	}); err != nil {
+		return err
+	}
+
+	slices.SortFunc(revisions.Items, func(a, b v1.WorkflowRevision) int {
+		return int(b.Spec.Revision - a.Spec.Revision)
+	})
+
+	resp := types.WorkflowRevisionList{Items: make([]types.WorkflowRevision, 0, len(revisions.Items))}
+	for _, revision := range revisions.Items {
+		resp.Items = append(resp.Items, convertWorkflowRevision(wf, revision))
+	}
+
+	return req.Write(resp)
+}
+
+func (a *WorkflowHandler) GetRevision(req api.Context) error {
+	wf, revision, err := getWorkflowRevision(req, req.PathValue("revision"))
+	if err != nil {
+		return err
+	}
+
+	return req.Write(convertWorkflowRevision(wf, revision))
+}
+
+func (a *WorkflowHandler) DiffRevisions(req api.Context) error {
+	wf, from, err := getWorkflowRevision(req, req.PathValue("revision"))
+	if err != nil {
+		return err
+	}
+
+	// Compare to the latest revision unless another one is requested.
+	toRevision := req.URL.Query().Get("to")
+	if toRevision == "" {
+		toRevision = strconv.FormatInt(wf.Status.Revision, 10)
+	}
+
+	_, to, err := getWorkflowRevision(req, toRevision)
+	if err != nil {
+		return err
+	}
+
+	diff, err := diffManifests(from.Spec.Manifest, to.Spec.Manifest)
+	if err != nil {
+		return err
+	}
+
+	return req.Write(types.WorkflowRevisionDiff{
+		WorkflowID: wf.Name,
+		From:       from.Spec.Revision,
+		To:         to.Spec.Revision,
+		Diff:       diff,
+	})
+}
+
+func (a *WorkflowHandler) Rollback(req api.Context) error {
+	wf, revision, err := getWorkflowRevision(req, req.PathValue("revision"))
+	if err != nil {
+		return err
+	}
+
+	// The rollback is saved as a new revision, the history is never rewritten.
+	wf.Spec.Manifest = revision.Spec.Manifest
+	if err := req.Update(&wf); err != nil {
+		return err
+	}
+
+	var knowledgeSet v1.KnowledgeSet
+	if len(wf.Status.KnowledgeSetNames) > 0 {
+		if err := req.Get(&knowledgeSet, wf.Status.KnowledgeSetNames[0]); err != nil {
+			return fmt.Errorf("failed to get workflow knowledge set: %w", err)
+		}
+	}
+
+	resp, err := convertWorkflow(wf, knowledgeSet.Status.TextEmbeddingModel, req.APIBaseURL)
+	if err != nil {
+		return err
+	}
+
+	return req.Write(resp)
+}
+
+func getWorkflowRevision(req api.Context, revisionNumber string) (v1.Workflow, v1.WorkflowRevision, error) {
+	var (
+		id       = req.PathValue("id")
+		wf       v1.Workflow
+		revision v1.WorkflowRevision
+	)
+
+	if err := req.Get(&wf, id); err != nil {
+		return wf, revision, err
+	}
+
+	number, err := strconv.ParseInt(revisionNumber, 10, 64)
+	if err != nil || number <= 0 {
+		return wf, revision, types.NewErrBadRequest("invalid revision %q", revisionNumber)
+	}
+
+	if err := req.Get(&revision, workflow.RevisionName(wf.Name, number)); kclient.IgnoreNotFound(err) != nil {
+		return wf, revision, err
+	} else if err != nil {
+		return wf, revision, types.NewErrNotFound("revision %d of workflow %s not found", number, id)
+	}
+
+	return wf, revision, nil
+}
+
+// diffManifests returns a line diff of the YAML of the two manifests, with lines prefixed by "+", "-" or " ".
+func diffManifests(from, to types.WorkflowManifest) (string, error) {
+	fromYAML, err := yaml.Marshal(from)
+	if err != nil {
+		return "", err
+	}
+	toYAML, err := yaml.Marshal(to)
+	if err != nil {
+		return "", err
+	}
+
+	dmp := diffmatchpatch.New()
+	fromChars, toChars, lines := dmp.DiffLinesToChars(string(fromYAML), string(toYAML))
+	diffs := dmp.DiffCharsToLines(dmp.DiffMain(fromChars, toChars, false), lines)
+
+	var sb strings.Builder
+	for _, diff := range diffs {
+		prefix := " "
+		switch diff.Type {
+		case diffmatchpatch.DiffInsert:
+			prefix = "+"
+		case diffmatchpatch.DiffDelete:
+			prefix = "-"
+		}
+		for _, line := range strings.SplitAfter(diff.Text, "\n") {
+			if line == "" {
+				continue
+			}
+			sb.WriteString(prefix)
+			sb.WriteString(line)
+		}
+	}
+	return sb.String(), nil
+}
+
+func convertWorkflowRevision(wf v1.Workflow, revision v1.WorkflowRevision) types.WorkflowRevision {
+	return types.WorkflowRevision{
+		Metadata:   MetadataFrom(&revision),
+		WorkflowID: wf.Name,
+		Revision:   revision.Spec.Revision,
+		Current:    revision.Spec.Revision == wf.Status.Revision,
+		Manifest:   revision.Spec.Manifest,
+	}
+}
@@ -5,17 +5,22 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/gptscript-ai/go-gptscript"
 	"github.com/obot-platform/obot/apiclient/types"
 	"github.com/obot-platform/obot/pkg/alias"
 	"github.com/obot-platform/obot/pkg/api"
+	"github.com/obot-platform/obot/pkg/controller/handlers/retention"
 	"github.com/obot-platform/obot/pkg/controller/handlers/workflow"
 	"github.com/obot-platform/obot/pkg/invoke"
 	"github.com/obot-platform/obot/pkg/render"
 	v1 "github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1"
 	"github.com/obot-platform/obot/pkg/system"
 	"github.com/obot-platform/obot/pkg/wait"
+	"github.com/obot-platform/obot/pkg/workflowgraph"
+	"github.com/obot-platform/obot/pkg/workflowparams"
+	"github.com/obot-platform/obot/pkg/workflowvalidate"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	kclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -24,13 +29,15 @@ type WorkflowHandler struct {
 	gptscript *gptscript.GPTScript
 	serverURL string
 	invoker   *invoke.Invoker
+	retention types.WorkflowRetention
 }
 
-func NewWorkflowHandler(gClient *gptscript.GPTScript, serverURL string, invoker *invoke.Invoker) *WorkflowHandler {
+func NewWorkflowHandler(gClient *gptscript.GPTScript, serverURL string, invoker *invoke.Invoker, retention types.WorkflowRetention) *WorkflowHandler {
 	return &WorkflowHandler{
 		gptscript: gClient,
 		serverURL: serverURL,
 		invoker:   invoker,
+		retention: retention,
 	}
 }
 
@@ -112,6 +119,10 @@ func (a *WorkflowHandler) Update(req api.Context) error {
 		return err
 	}
 
+	if err := validateWorkflow(req, manifest); err != nil {
+		return err
+	}
+
 	manifest = workflow.PopulateIDs(manifest)
 
 	if err := req.Get(&wf, id); err != nil {
@@ -169,6 +180,10 @@ func (a *WorkflowHandler) Create(req api.Context) error {
 		return err
 	}
 
+	if err := validateWorkflow(req, manifest); err != nil {
+		return err
+	}
+
 	if manifest.Model != "" {
 		// Get the model to ensure it is active
 		var model v1.Model
@@ -205,6 +220,42 @@ func (a *WorkflowHandler) Create(req api.Context) error {
 	return req.WriteCreated(resp)
 }
 
+// Validate checks a workflow manifest without saving it and returns all of its problems.
+func (a *WorkflowHandler) Validate(req api.Context) error {
+	var manifest types.WorkflowManifest
+	if err := req.Read(&manifest); err != nil {
+		return err
+	}
+
+	problems, err := workflowvalidate.Validate(req.Context(), req.Storage, req.Namespace(), manifest)
+	if err != nil {
+		return err
+	}
+
+	resp := types.WorkflowValidation{
+		Problems: make([]types.WorkflowValidationProblem, 0, len(problems)),
+	}
+	for _, problem := range problems {
+		resp.Problems = append(resp.Problems, types.WorkflowValidationProblem{
+			Path:    problem.Path,
+			Message: problem.Message,
+		})
+	}
+
+	return req.Write(resp)
+}
+
+func validateWorkflow(req api.Context, manifest types.WorkflowManifest) error {
+	problems, err := workflowvalidate.Validate(req.Context(), req.Storage, req.Namespace(), manifest)
+	if err != nil {
+		return err
+	}
+	if len(problems) > 0 {
+		return types.NewErrBadRequest("invalid workflow:\n%v", workflowvalidate.Error(problems))
+	}
+	return nil
+}
+
 func convertWorkflow(workflow v1.Workflow, textEmbeddingModel, baseURL string) (*types.Workflow, error) {
 	var links []string
 	if baseURL != "" {
@@ -227,6 +278,7 @@ func convertWorkflow(workflow v1.Workflow, textEmbeddingModel, baseURL string) (
 	return &types.Workflow{
 		Metadata:           MetadataFrom(&workflow, links...),
 		WorkflowManifest:   workflow.Spec.Manifest,
+		Revision:           workflow.Status.Revision,
 		ThreadID:           workflow.Spec.ThreadName,
 		AliasAssigned:      aliasAssigned,
 		AuthStatus:         workflow.Status.AuthStatus,
@@ -257,6 +309,12 @@ func (a *WorkflowHandler) ByID(req api.Context) error {
 		return err
 	}
 
+	depths, err := queueDepths(req, workflow.Name)
+	if err != nil {
+		return err
+	}
+	resp.QueueDepth = depths[workflow.Name]
+
 	return req.WriteCreated(resp)
 }
 
@@ -276,6 +334,11 @@ func (a *WorkflowHandler) List(req api.Context) error {
 		textEmbeddingModels[knowledgeSet.Name] = knowledgeSet.Status.TextEmbeddingModel
 	}
 
+	depths, err := queueDepths(req, "")
+	if err != nil {
+		return err
+	}
+
 	var textEmbeddingModel string
 	resp := make([]types.Workflow, 0, len(workflowList.Items))
 	for _, workflow := range workflowList.Items {
@@ -288,6 +351,7 @@ func (a *WorkflowHandler) List(req api.Context) error {
 		if err != nil {
 			return err
 		}
+		convertedWorkflow.QueueDepth = depths[workflow.Name]
 
 		resp = append(resp, *convertedWorkflow)
 	}
@@ -295,6 +359,30 @@ func (a *WorkflowHandler) List(req api.Context) error {
 	return req.Write(types.WorkflowList{Items: resp})
 }
 
+// queueDepths returns the number of queued executions by workflow name, for a single workflow if a name is given.
+func queueDepths(req api.Context, workflowName string) (map[string]int, error) {
+	var (
+		wfes    v1.WorkflowExecutionList
+		options []kclient.ListOption
+	)
+	if workflowName != "" {
+		options = append(options, kclient.MatchingFields{
+			"spec.workflowName": workflowName,
+		})
+	}
+	if err := req.List(&wfes, options...); err != nil {
+		return nil, err
+	}
+
+	result := map[string]int{}
+	for _, wfe := range wfes.Items {
+		if wfe.Status.State == types.WorkflowStateQueued {
+			result[wfe.Spec.WorkflowName]++
+		}
+	}
+	return result, nil
+}
+
 func (a *WorkflowHandler) EnsureCredentialForKnowledgeSource(req api.Context) error {
 	var wf v1.Workflow
 	if err := req.Get(&wf, req.PathValue("id")); err != nil {
@@ -401,6 +489,96 @@ func (a *WorkflowHandler) WorkflowExecutions(req api.Context) error {
 	return req.Write(resp)
 }
 
+// Test starts a dry run of the workflow, where the model responses of the steps come from the fixtures in the
+// request instead of the model.
+func (a *WorkflowHandler) Test(req api.Context) error {
+	var (
+		id    = req.PathValue("id")
+		wf    v1.Workflow
+		input types.WorkflowTestRequest
+	)
+
+	if err := req.Read(&input); err != nil {
+		return err
+	}
+
+	if err := req.Get(&wf, id); err != nil {
+		return err
+	}
+
+	workflowInput, err := workflowparams.Validate(wf.Spec.Manifest.InputParams, input.Input)
+	if err != nil {
+		return types.NewErrBadRequest("%v", err)
+	}
+
+	wfe := v1.WorkflowExecution{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: system.WorkflowExecutionPrefix,
+			Namespace:    req.Namespace(),
+		},
+		Spec: v1.WorkflowExecutionSpec{
+			WorkflowName: wf.Name,
+			Input:        workflowInput,
+			DryRun: &v1.WorkflowDryRun{
+				Responses: input.Responses,
+				CallTools: input.CallTools,
+			},
+		},
+	}
+	if err := req.Create(&wfe); err != nil {
+		return err
+	}
+
+	return req.WriteCreated(convertWorkflowExecution(wfe))
+}
+
+// Retention reports the executions of the workflow that the next garbage collection deletes, without deleting them.
+func (a *WorkflowHandler) Retention(req api.Context) error {
+	var wf v1.Workflow
+	if err := req.Get(&wf, req.PathValue("id")); err != nil {
+		return err
+	}
+
+	expired, err := retention.Plan(req.Context(), req.Storage, &wf, a.retention, time.Now())
+	if err != nil {
+		return err
+	}
+
+	resp := types.WorkflowRetentionReport{
+		Retention: retention.Effective(wf.Spec.Manifest, a.retention),
+		Items:     make([]types.WorkflowRetentionItem, 0, len(expired)),
+	}
+	for _, e := range expired {
+		var endTime *types.Time
+		if e.Execution.Status.EndTime != nil {
+			endTime = types.NewTime(e.Execution.Status.EndTime.Time)
+		}
+		resp.Items = append(resp.Items, types.WorkflowRetentionItem{
+			WorkflowExecutionID: e.Execution.Name,
+			State:               e.Execution.Status.State,
+			EndTime:             endTime,
+			Reason:              e.Reason,
+		})
+	}
+
+	return req.Write(resp)
+}
+
+// Graph renders the definition of the workflow as a Mermaid or Graphviz DOT graph.
+func (a *WorkflowHandler) Graph(req api.Context) error {
+	var wf v1.Workflow
+	if err := req.Get(&wf, req.PathValue("id")); err != nil {
+		return err
+	}
+
+	graph, err := workflowgraph.FromManifest(workflow.PopulateIDs(wf.Spec.Manifest)).Render(req.URL.Query().Get("format"))
+	if err != nil {
+		return types.NewErrBadRequest("%v", err)
+	}
+
+	return req.Write(graph)
+}
+
 func (a *WorkflowHandler) Script(req api.Context) error {
 	var (
 		id     = req.Request.PathValue("id")
@@ -17,7 +17,8 @@ func Router(services *services.Services) (http.Handler, error) {
 	assistants := handlers.NewAssistantHandler(services.Invoker, services.Events, services.GPTClient, services.Router.Backend())
 	tools := handlers.NewToolHandler(services.GPTClient, services.Invoker)
 	tasks := handlers.NewTaskHandler(services.Invoker, services.Events)
-	workflows := handlers.NewWorkflowHandler(services.GPTClient, services.ServerURL, services.Invoker)
+	workflows := handlers.NewWorkflowHandler(services.GPTClient, services.ServerURL, services.Invoker, services.WorkflowRetention)
+	workflowExecutions := handlers.NewWorkflowExecutionHandler()
 	invoker := handlers.NewInvokeHandler(services.Invoker)
 	threads := handlers.NewThreadHandler(services.GPTClient, services.Events)
 	runs := handlers.NewRunHandler(services.Events)
@@ -162,16 +163,34 @@ func Router(services *services.Services) (http.Handler, error) {
 	mux.HandleFunc("GET /api/workflows", workflows.List)
 	mux.HandleFunc("GET /api/workflows/{id}", workflows.ByID)
 	mux.HandleFunc("GET /api/workflows/{id}/executions", workflows.WorkflowExecutions)
+	mux.HandleFunc("GET /api/workflows/{id}/executions/{execution_id}/approvals", workflows.ListApprovals)
+	mux.HandleFunc("POST /api/workflows/{id}/executions/{execution_id}/approve", workflows.Approve)
+	mux.HandleFunc("POST /api/workflows/{id}/executions/{execution_id}/reject", workflows.Reject)
+	mux.HandleFunc("GET /api/workflows/{id}/revisions", workflows.ListRevisions)
+	mux.HandleFunc("GET /api/workflows/{id}/revisions/{revision}", workflows.GetRevision)
+	mux.HandleFunc("GET /api/workflows/{id}/revisions/{revision}/diff", workflows.DiffRevisions)
+	mux.HandleFunc("POST /api/workflows/{id}/revisions/{revision}/rollback", workflows.Rollback)
+	mux.HandleFunc("POST /api/workflows/{id}/test", workflows.Test)
+	mux.HandleFunc("GET /api/workflows/{id}/graph", workflows.Graph)
+	mux.HandleFunc("GET /api/workflows/{id}/retention", workflows.Retention)
 	mux.HandleFunc("GET /api/workflows/{id}/script", workflows.Script)
 	mux.HandleFunc("GET /api/workflows/{id}/script.gpt", workflows.Script)
 	mux.HandleFunc("GET /api/workflows/{id}/script/tool.gpt", workflows.Script)
 	mux.HandleFunc("POST /api/workflows", workflows.Create)
+	mux.HandleFunc("POST /api/workflows/validate", workflows.Validate)
 	mux.HandleFunc("POST /api/workflows/{id}/authenticate", workflows.Authenticate)
 	mux.HandleFunc("POST /api/workflows/{id}/deauthenticate", workflows.DeAuthenticate)
 	mux.HandleFunc("PUT /api/workflows/{id}", workflows.Update)
 	mux.HandleFunc("DELETE /api/workflows/{id}", workflows.Delete)
 	mux.HandleFunc("POST /api/workflows/{id}/oauth-credentials/{ref}/login", workflows.EnsureCredentialForKnowledgeSource)
 
+	// Workflow Executions
+	mux.HandleFunc("GET /api/workflow-executions/{id}", workflowExecutions.ByID)
+	mux.HandleFunc("GET /api/workflow-executions/{id}/steps", workflowExecutions.Steps)
+	mux.HandleFunc("GET /api/workflow-executions/{id}/graph", workflowExecutions.Graph)
+	mux.HandleFunc("POST /api/workflow-executions/{id}/cancel", workflowExecutions.Cancel)
+	mux.HandleFunc("POST /api/workflow-callbacks/{namespace}/{id}/{step}", workflowExecutions.Callback)
+
 	// Workflow knowledge files
 	mux.HandleFunc("GET /api/workflows/{agent_id}/knowledge-files", agents.ListKnowledgeFiles)
 	mux.HandleFunc("POST /api/workflows/{id}/knowledge-files/{file...}", agents.UploadKnowledgeFile)
@@ -289,6 +308,9 @@ func Router(services *services.Services) (http.Handler, error) {
 	mux.HandleFunc("DELETE /api/cronjobs/{id}", cronJobs.Delete)
 	mux.HandleFunc("PUT /api/cronjobs/{id}", cronJobs.Update)
 	mux.HandleFunc("POST /api/cronjobs/{id}", cronJobs.Execute)
+	mux.HandleFunc("POST /api/cronjobs/{id}/run", cronJobs.Run)
+	mux.HandleFunc("POST /api/cronjobs/{id}/suspend", cronJobs.Suspend)
+	mux.HandleFunc("POST /api/cronjobs/{id}/resume", cronJobs.Resume)
 
 	// debug
 	mux.HTTPHandle("GET /debug/pprof/", http.DefaultServeMux)
@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/obot-platform/obot/apiclient/types"
+	"github.com/spf13/cobra"
+)
+
+type CronJobs struct {
+	root   *Obot
+	Quiet  bool   `usage:"Only print IDs of cron jobs" short:"q"`
+	Wide   bool   `usage:"Print more information" short:"w"`
+	Output string `usage:"Output format (table, json, yaml)" short:"o" default:"table"`
+}
+
+func (l *CronJobs) Customize(cmd *cobra.Command) {
+	cmd.Use = "cronjobs [flags] [CRONJOB...]"
+	cmd.Aliases = []string{"cronjob", "cron"}
+}
+
+func (l *CronJobs) Run(cmd *cobra.Command, args []string) error {
+	var (
+		cjs types.CronJobList
+		err error
+	)
+
+	if len(args) > 0 {
+		for _, arg := range args {
+			cj, err := l.root.Client.GetCronJob(cmd.Context(), arg)
+			if err != nil {
+				return err
+			}
+			cjs.Items = append(cjs.Items, *cj)
+		}
+	} else {
+		cjs, err = l.root.Client.ListCronJobs(cmd.Context())
+		if err != nil {
+			return err
+		}
+	}
+
+	if ok, err := output(l.Output, cjs); ok || err != nil {
+		return err
+	}
+
+	if l.Quiet {
+		for _, cj := range cjs.Items {
+			fmt.Println(cj.ID)
+		}
+		return nil
+	}
+
+	w := newTable("ID", "DESCRIPTION", "WORKFLOW", "SCHEDULE", "TIMEZONE", "SUSPENDED", "NEXTRUN", "LASTRUN", "CREATED")
+	for _, cj := range cjs.Items {
+		var nextRun string
+		if cj.NextRunAt != nil {
+			nextRun = humanize.Time(cj.NextRunAt.Time)
+		}
+		w.WriteRow(cj.ID, truncate(cj.Description, l.Wide), cj.Workflow, cj.Schedule, cj.Timezone,
+			strconv.FormatBool(cj.Suspend), nextRun,
+			humanize.Time(cj.LastRunStartedAt.GetTime()),
+			humanize.Time(cj.Created.Time))
+	}
+
+	return w.Err()
+}
+
+type CronJobRun struct {
+	root *Obot
+}
+
+func (l *CronJobRun) Customize(cmd *cobra.Command) {
+	cmd.Use = "run [flags] CRONJOB..."
+	cmd.Args = cobra.MinimumNArgs(1)
+}
+
+func (l *CronJobRun) Run(cmd *cobra.Command, args []string) error {
+	for _, id := range args {
+		wfe, err := l.root.Client.RunCronJob(cmd.Context(), id)
+		if err != nil {
+			return err
+		}
+		fmt.Println("Started workflow execution:", wfe.ID)
+	}
+	return nil
+}
+
+type CronJobSuspend struct {
+	root *Obot
+}
+
+func (l *CronJobSuspend) Customize(cmd *cobra.Command) {
+	cmd.Use = "suspend [flags] CRONJOB..."
+	cmd.Args = cobra.MinimumNArgs(1)
+}
+
+func (l *CronJobSuspend) Run(cmd *cobra.Command, args []string) error {
+	for _, id := range args {
+		if _, err := l.root.Client.SuspendCronJob(cmd.Context(), id); err != nil {
+			return err
+		}
+		fmt.Println("Suspended cron job:", id)
+	}
+	return nil
+}
+
+type CronJobResume struct {
+	root *Obot
+}
+
+func (l *CronJobResume) Customize(cmd *cobra.Command) {
+	cmd.Use = "resume [flags] CRONJOB..."
+	cmd.Args = cobra.MinimumNArgs(1)
+}
+
+func (l *CronJobResume) Run(cmd *cobra.Command, args []string) error {
+	for _, id := range args {
+		if _, err := l.root.Client.ResumeCronJob(cmd.Context(), id); err != nil {
+			return err
+		}
+		fmt.Println("Resumed cron job:", id)
+	}
+	return nil
+}
+
+type CronJobNextRuns struct {
+	root   *Obot
+	Output string `usage:"Output format (table, json, yaml)" short:"o" default:"table"`
+}
+
+func (l *CronJobNextRuns) Customize(cmd *cobra.Command) {
+	cmd.Use = "next-runs [flags] CRONJOB..."
+	cmd.Args = cobra.MinimumNArgs(1)
+}
+
+func (l *CronJobNextRuns) Run(cmd *cobra.Command, args []string) error {
+	var cjs types.CronJobList
+	for _, id := range args {
+		cj, err := l.root.Client.GetCronJob(cmd.Context(), id)
+		if err != nil {
+			return err
+		}
+		cjs.Items = append(cjs.Items, *cj)
+	}
+
+	if ok, err := output(l.Output, cjs); ok || err != nil {
+		return err
+	}
+
+	w := newTable("ID", "SCHEDULE", "TIMEZONE", "TIME", "IN")
+	for _, cj := range cjs.Items {
+		loc := time.Local
+		if cj.Timezone != "" {
+			if tz, err := time.LoadLocation(cj.Timezone); err == nil {
+				loc = tz
+			}
+		}
+		for _, next := range cj.NextRuns {
+			w.WriteRow(cj.ID, cj.Schedule, cj.Timezone, next.Time.In(loc).Format(time.RFC3339), humanize.Time(next.Time))
+		}
+	}
+
+	return w.Err()
+}
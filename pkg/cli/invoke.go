@@ -12,12 +12,14 @@ import (
 )
 
 type Invoke struct {
-	Thread  string `usage:"Thread name to run the agent in." short:"t"`
-	Step    string `usage:"Workflow step to rerun from, thread is already required" short:"s"`
-	Quiet   *bool  `usage:"Only print output characters" short:"q"`
-	Verbose bool   `usage:"Print more information" short:"v"`
-	Async   bool   `usage:"Run the agent asynchronously" short:"a"`
-	root    *Obot
+	Thread    string  `usage:"Thread name to run the agent in." short:"t"`
+	Step      string  `usage:"Workflow step to rerun from, thread is already required" short:"s"`
+	Quiet     *bool   `usage:"Only print output characters" short:"q"`
+	Verbose   bool    `usage:"Print more information" short:"v"`
+	Async     bool    `usage:"Run the agent asynchronously" short:"a"`
+	MaxTokens int     `usage:"Maximum tokens a new workflow execution may use"`
+	MaxCost   float64 `usage:"Maximum estimated cost in USD a new workflow execution may use"`
+	root      *Obot
 }
 
 func (l *Invoke) GetQuiet() bool {
@@ -46,10 +48,12 @@ func (l *Invoke) Run(cmd *cobra.Command, args []string) error {
 	}
 
 	return invokeclient.Invoke(cmd.Context(), l.root.Client, args[0], strings.Join(args[1:], " "), invokeclient.Options{
-		ThreadID: l.Thread,
-		Quiet:    l.GetQuiet(),
-		Details:  l.Verbose,
-		Async:    l.Async,
-		Step:     l.Step,
+		ThreadID:  l.Thread,
+		Quiet:     l.GetQuiet(),
+		Details:   l.Verbose,
+		Async:     l.Async,
+		Step:      l.Step,
+		MaxTokens: l.MaxTokens,
+		MaxCost:   l.MaxCost,
 	})
 }
@@ -20,6 +20,9 @@ type Options struct {
 	Details  bool
 	Async    bool
 	Step     string
+	// MaxTokens and MaxCost override the budget of the workflow for a new execution.
+	MaxTokens int
+	MaxCost   float64
 }
 
 func Invoke(ctx context.Context, c *apiclient.Client, id, input string, opts Options) (err error) {
@@ -50,6 +53,8 @@ func Invoke(ctx context.Context, c *apiclient.Client, id, input string, opts Opt
 			ThreadID:       threadID,
 			Async:          opts.Async,
 			WorkflowStepID: opts.Step,
+			MaxTokens:      opts.MaxTokens,
+			MaxCost:        opts.MaxCost,
 		})
 		if err != nil {
 			return err
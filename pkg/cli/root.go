@@ -45,7 +45,18 @@ func New() *cobra.Command {
 		&Create{root: root},
 		&Agents{root: root},
 		cmd.Command(&Workflows{root: root},
-			&WorkflowAuth{root: root}),
+			&WorkflowAuth{root: root},
+			&WorkflowApprovals{root: root},
+			&WorkflowApprove{root: root},
+			&WorkflowReject{root: root},
+			&WorkflowRevisions{root: root},
+			&WorkflowDiff{root: root},
+			&WorkflowRollback{root: root},
+			&WorkflowTest{root: root},
+			&WorkflowGraph{root: root},
+			&WorkflowValidate{root: root},
+			&WorkflowRetention{root: root}),
+		cmd.Command(&WorkflowExecutions{root: root}, &WorkflowExecutionCancel{root: root}, &WorkflowExecutionGraph{root: root}),
 		&Edit{root: root},
 		&Update{root: root},
 		&Delete{root: root},
@@ -58,6 +69,11 @@ func New() *cobra.Command {
 			&ToolRegister{root: root},
 			&ToolUpdate{root: root}),
 		&Webhooks{root: root},
+		cmd.Command(&CronJobs{root: root},
+			&CronJobRun{root: root},
+			&CronJobSuspend{root: root},
+			&CronJobResume{root: root},
+			&CronJobNextRuns{root: root}),
 		&Server{},
 		&Version{},
 	)
@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/dustin/go-humanize"
+	"github.com/obot-platform/obot/apiclient/types"
+	"github.com/spf13/cobra"
+)
+
+type WorkflowApprovals struct {
+	root   *Obot
+	Wide   bool   `usage:"Print more information" short:"w"`
+	Output string `usage:"Output format (table, json, yaml)" short:"o" default:"table"`
+}
+
+func (l *WorkflowApprovals) Customize(cmd *cobra.Command) {
+	cmd.Use = "approvals [flags] WORKFLOW_ID EXECUTION_ID"
+	cmd.Args = cobra.ExactArgs(2)
+}
+
+func (l *WorkflowApprovals) Run(cmd *cobra.Command, args []string) error {
+	approvals, err := l.root.Client.ListWorkflowApprovals(cmd.Context(), args[0], args[1])
+	if err != nil {
+		return err
+	}
+
+	if ok, err := output(l.Output, approvals); ok || err != nil {
+		return err
+	}
+
+	w := newTable("STEP", "STATE", "MESSAGE", "DECISION", "BY", "COMMENT", "REQUESTED")
+	for _, approval := range approvals.Items {
+		w.WriteRow(approval.StepID, string(approval.State), truncate(approval.Message, l.Wide), approval.Decision,
+			approval.DecidedBy, truncate(approval.Comment, l.Wide), humanize.Time(approval.RequestedAt.Time))
+	}
+
+	return w.Err()
+}
+
+type WorkflowApprove struct {
+	root    *Obot
+	Step    string `usage:"ID of the step to approve, required if more than one step is waiting"`
+	Comment string `usage:"Comment for the approval" short:"m"`
+}
+
+func (l *WorkflowApprove) Customize(cmd *cobra.Command) {
+	cmd.Use = "approve [flags] WORKFLOW_ID EXECUTION_ID"
+	cmd.Args = cobra.ExactArgs(2)
+}
+
+func (l *WorkflowApprove) Run(cmd *cobra.Command, args []string) error {
+	approval, err := l.root.Client.ApproveWorkflowExecution(cmd.Context(), args[0], args[1], types.WorkflowApprovalDecision{
+		StepID:  l.Step,
+		Comment: l.Comment,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Println("Approved step:", approval.StepID)
+	return nil
+}
+
+type WorkflowReject struct {
+	root    *Obot
+	Step    string `usage:"ID of the step to reject, required if more than one step is waiting"`
+	Comment string `usage:"Reason for the rejection, reported as the error of the execution" short:"m"`
+}
+
+func (l *WorkflowReject) Customize(cmd *cobra.Command) {
+	cmd.Use = "reject [flags] WORKFLOW_ID EXECUTION_ID"
+	cmd.Args = cobra.ExactArgs(2)
+}
+
+func (l *WorkflowReject) Run(cmd *cobra.Command, args []string) error {
+	approval, err := l.root.Client.RejectWorkflowExecution(cmd.Context(), args[0], args[1], types.WorkflowApprovalDecision{
+		StepID:  l.Step,
+		Comment: l.Comment,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Println("Rejected step:", approval.StepID)
+	return nil
+}
@@ -0,0 +1,246 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/obot-platform/obot/apiclient/types"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+)
+
+type WorkflowTest struct {
+	root     *Obot
+	Fixtures string `usage:"File with the step responses and assertions" short:"f"`
+	Input    string `usage:"Input of the workflow, overrides the input in the fixtures file" short:"i"`
+	Timeout  string `usage:"How long to wait for the workflow to finish" default:"5m"`
+	Wide     bool   `usage:"Print the full output of the steps" short:"w"`
+}
+
+func (l *WorkflowTest) Customize(cmd *cobra.Command) {
+	cmd.Use = "test [flags] FILE"
+	cmd.Args = cobra.ExactArgs(1)
+}
+
+type workflowFixtures struct {
+	Input      string                      `json:"input,omitempty"`
+	Responses  map[string]fixtureResponses `json:"responses,omitempty"`
+	CallTools  bool                        `json:"callTools,omitempty"`
+	Assertions []workflowAssertion         `json:"assertions,omitempty"`
+}
+
+// fixtureResponses is a list of responses that can be written as a single string in the fixtures file.
+type fixtureResponses []string
+
+func (f *fixtureResponses) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*f = fixtureResponses{single}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(f))
+}
+
+type workflowAssertion struct {
+	// Step is the ID of the step, or empty for the workflow itself.
+	Step     string `json:"step,omitempty"`
+	Ran      *bool  `json:"ran,omitempty"`
+	State    string `json:"state,omitempty"`
+	Output   string `json:"output,omitempty"`
+	Contains string `json:"contains,omitempty"`
+}
+
+func (l *WorkflowTest) Run(cmd *cobra.Command, args []string) error {
+	timeout, err := time.ParseDuration(l.Timeout)
+	if err != nil {
+		return fmt.Errorf("invalid timeout %q: %w", l.Timeout, err)
+	}
+
+	fixtures, err := readFixtures(l.Fixtures)
+	if err != nil {
+		return err
+	}
+	if l.Input != "" {
+		fixtures.Input = l.Input
+	}
+
+	workflowID, err := l.createWorkflow(cmd, args[0])
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := l.root.Client.DeleteWorkflow(cmd.Context(), workflowID); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to delete workflow %s: %v\n", workflowID, err)
+		}
+	}()
+
+	responses := make(map[string][]string, len(fixtures.Responses))
+	for stepID, stepResponses := range fixtures.Responses {
+		responses[stepID] = stepResponses
+	}
+
+	wfe, err := l.root.Client.TestWorkflow(cmd.Context(), workflowID, types.WorkflowTestRequest{
+		Input:     fixtures.Input,
+		Responses: responses,
+		CallTools: fixtures.CallTools,
+	})
+	if err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(timeout)
+	for !wfe.State.IsTerminal() && !wfe.State.IsBlocked() {
+		if time.Now().After(deadline) {
+			_, _ = l.root.Client.CancelWorkflowExecution(cmd.Context(), wfe.ID)
+			return fmt.Errorf("workflow execution %s did not finish within %s", wfe.ID, timeout)
+		}
+		time.Sleep(time.Second)
+		if wfe, err = l.root.Client.GetWorkflowExecution(cmd.Context(), wfe.ID); err != nil {
+			return err
+		}
+	}
+
+	steps, err := l.root.Client.ListWorkflowExecutionSteps(cmd.Context(), wfe.ID)
+	if err != nil {
+		return err
+	}
+
+	w := newTable("STEP", "STATE", "OUTPUT")
+	for _, step := range steps.Items {
+		out := step.Output
+		if step.Error != "" {
+			out = step.Error
+		}
+		w.WriteRow(step.StepID, string(step.State), truncate(out, l.Wide))
+	}
+	if err := w.Err(); err != nil {
+		return err
+	}
+
+	fmt.Printf("\nWorkflow %s: %s\n", wfe.State, truncate(wfe.Output+wfe.Error, l.Wide))
+
+	if len(fixtures.Assertions) == 0 {
+		if wfe.State != types.WorkflowStateComplete {
+			return fmt.Errorf("workflow did not complete")
+		}
+		return nil
+	}
+
+	fmt.Println()
+	var failed int
+	for _, assertion := range fixtures.Assertions {
+		problems := checkAssertion(assertion, *wfe, steps.Items)
+		name := assertion.Step
+		if name == "" {
+			name = "workflow"
+		}
+		if len(problems) == 0 {
+			fmt.Printf("PASS %s\n", name)
+			continue
+		}
+		failed++
+		fmt.Printf("FAIL %s: %s\n", name, strings.Join(problems, "; "))
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d assertions failed", failed, len(fixtures.Assertions))
+	}
+	return nil
+}
+
+func readFixtures(file string) (result workflowFixtures, _ error) {
+	if file == "" {
+		return result, nil
+	}
+
+	input, err := readInput(file)
+	if err != nil {
+		return result, err
+	}
+	defer input.Close()
+
+	data, err := io.ReadAll(input)
+	if err != nil {
+		return result, err
+	}
+
+	if err := yaml.Unmarshal(data, &result); err != nil {
+		return result, fmt.Errorf("failed to parse fixtures %s: %w", file, err)
+	}
+	return result, nil
+}
+
+func (l *WorkflowTest) createWorkflow(cmd *cobra.Command, file string) (string, error) {
+	input, err := readInput(file)
+	if err != nil {
+		return "", err
+	}
+	defer input.Close()
+
+	data, err := io.ReadAll(input)
+	if err != nil {
+		return "", err
+	}
+
+	manifests, err := parseManifests(data)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse manifest: %v", err)
+	}
+
+	for _, manifest := range manifests {
+		if strings.EqualFold(manifest.Type, "workflow") {
+			return l.root.Client.Create(cmd.Context(), manifest.Type, manifest.Data)
+		}
+	}
+
+	return "", fmt.Errorf("no workflow found in %s", file)
+}
+
+// checkAssertion returns why the assertion doesn't hold for the execution. Assertions about a step that ran more than
+// once, like a step in a loop, are checked against the last run.
+func checkAssertion(assertion workflowAssertion, wfe types.WorkflowExecution, steps []types.WorkflowExecutionStep) (problems []string) {
+	var (
+		ran    = true
+		state  = wfe.State
+		output = wfe.Output
+	)
+	if assertion.Step != "" {
+		ran = false
+		for _, step := range steps {
+			if step.StepID == assertion.Step {
+				ran = true
+				state = step.State
+				output = step.Output
+			}
+		}
+	}
+
+	if assertion.Ran != nil && *assertion.Ran != ran {
+		if ran {
+			problems = append(problems, "step ran")
+		} else {
+			problems = append(problems, "step did not run")
+		}
+	}
+	if !ran {
+		if assertion.Ran == nil && (assertion.State != "" || assertion.Output != "" || assertion.Contains != "") {
+			problems = append(problems, "step did not run")
+		}
+		return problems
+	}
+
+	if assertion.State != "" && !strings.EqualFold(assertion.State, string(state)) {
+		problems = append(problems, fmt.Sprintf("state is %s, expected %s", state, assertion.State))
+	}
+	if assertion.Output != "" && strings.TrimSpace(output) != strings.TrimSpace(assertion.Output) {
+		problems = append(problems, fmt.Sprintf("output is %q, expected %q", output, assertion.Output))
+	}
+	if assertion.Contains != "" && !strings.Contains(output, assertion.Contains) {
+		problems = append(problems, fmt.Sprintf("output does not contain %q", assertion.Contains))
+	}
+	return problems
+}
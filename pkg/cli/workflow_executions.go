@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+type WorkflowExecutions struct {
+	root *Obot
+}
+
+func (l *WorkflowExecutions) Customize(cmd *cobra.Command) {
+	cmd.Use = "workflow-executions"
+	cmd.Aliases = []string{"workflow-execution", "wfe"}
+}
+
+func (l *WorkflowExecutions) Run(cmd *cobra.Command, _ []string) error {
+	return cmd.Help()
+}
+
+type WorkflowExecutionCancel struct {
+	root *Obot
+}
+
+func (l *WorkflowExecutionCancel) Customize(cmd *cobra.Command) {
+	cmd.Use = "cancel [flags] EXECUTION_ID..."
+	cmd.Args = cobra.MinimumNArgs(1)
+}
+
+func (l *WorkflowExecutionCancel) Run(cmd *cobra.Command, args []string) error {
+	for _, id := range args {
+		if _, err := l.root.Client.CancelWorkflowExecution(cmd.Context(), id); err != nil {
+			return err
+		}
+		fmt.Println("Cancelled workflow execution:", id)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+type WorkflowGraph struct {
+	root   *Obot
+	Format string `usage:"Graph format (mermaid, dot)" short:"f" default:"mermaid"`
+}
+
+func (l *WorkflowGraph) Customize(cmd *cobra.Command) {
+	cmd.Use = "graph [flags] WORKFLOW_ID"
+	cmd.Args = cobra.ExactArgs(1)
+}
+
+func (l *WorkflowGraph) Run(cmd *cobra.Command, args []string) error {
+	graph, err := l.root.Client.WorkflowGraph(cmd.Context(), args[0], l.Format)
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(graph)
+	return nil
+}
+
+type WorkflowExecutionGraph struct {
+	root   *Obot
+	Format string `usage:"Graph format (mermaid, dot)" short:"f" default:"mermaid"`
+}
+
+func (l *WorkflowExecutionGraph) Customize(cmd *cobra.Command) {
+	cmd.Use = "graph [flags] EXECUTION_ID"
+	cmd.Args = cobra.ExactArgs(1)
+}
+
+func (l *WorkflowExecutionGraph) Run(cmd *cobra.Command, args []string) error {
+	graph, err := l.root.Client.WorkflowExecutionGraph(cmd.Context(), args[0], l.Format)
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(graph)
+	return nil
+}
@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"github.com/dustin/go-humanize"
+	"github.com/obot-platform/obot/apiclient"
+	"github.com/obot-platform/obot/apiclient/types"
+	"github.com/spf13/cobra"
+)
+
+type WorkflowRetention struct {
+	root   *Obot
+	Output string `usage:"Output format (table, json, yaml)" short:"o" default:"table"`
+}
+
+func (l *WorkflowRetention) Customize(cmd *cobra.Command) {
+	cmd.Use = "retention [flags] [WORKFLOW...]"
+}
+
+func (l *WorkflowRetention) Run(cmd *cobra.Command, args []string) error {
+	ids := args
+	if len(ids) == 0 {
+		wfs, err := l.root.Client.ListWorkflows(cmd.Context(), apiclient.ListWorkflowsOptions{})
+		if err != nil {
+			return err
+		}
+		for _, wf := range wfs.Items {
+			ids = append(ids, wf.ID)
+		}
+	}
+
+	var reports []types.WorkflowRetentionReport
+	for _, id := range ids {
+		report, err := l.root.Client.GetWorkflowRetention(cmd.Context(), id)
+		if err != nil {
+			return err
+		}
+		reports = append(reports, *report)
+	}
+
+	if ok, err := output(l.Output, reports); ok || err != nil {
+		return err
+	}
+
+	w := newTable("WORKFLOW", "EXECUTION", "STATE", "ENDED", "REASON")
+	for i, report := range reports {
+		for _, item := range report.Items {
+			var ended string
+			if item.EndTime != nil {
+				ended = humanize.Time(item.EndTime.Time)
+			}
+			w.WriteRow(ids[i], item.WorkflowExecutionID, string(item.State), ended, item.Reason)
+		}
+	}
+
+	return w.Err()
+}
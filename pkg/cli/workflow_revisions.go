@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+)
+
+type WorkflowRevisions struct {
+	root   *Obot
+	Output string `usage:"Output format (table, json, yaml)" short:"o" default:"table"`
+}
+
+func (l *WorkflowRevisions) Customize(cmd *cobra.Command) {
+	cmd.Use = "revisions [flags] WORKFLOW_ID"
+	cmd.Args = cobra.ExactArgs(1)
+}
+
+func (l *WorkflowRevisions) Run(cmd *cobra.Command, args []string) error {
+	revisions, err := l.root.Client.ListWorkflowRevisions(cmd.Context(), args[0])
+	if err != nil {
+		return err
+	}
+
+	if ok, err := output(l.Output, revisions); ok || err != nil {
+		return err
+	}
+
+	w := newTable("REVISION", "CURRENT", "STEPS", "CREATED")
+	for _, revision := range revisions.Items {
+		current := ""
+		if revision.Current {
+			current = "*"
+		}
+		w.WriteRow(strconv.FormatInt(revision.Revision, 10), current, strconv.Itoa(len(revision.Manifest.Steps)),
+			humanize.Time(revision.Created.Time))
+	}
+
+	return w.Err()
+}
+
+type WorkflowDiff struct {
+	root *Obot
+}
+
+func (l *WorkflowDiff) Customize(cmd *cobra.Command) {
+	cmd.Use = "diff [flags] WORKFLOW_ID FROM_REVISION [TO_REVISION]"
+	cmd.Args = cobra.RangeArgs(2, 3)
+}
+
+func (l *WorkflowDiff) Run(cmd *cobra.Command, args []string) error {
+	from, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid revision %q: %w", args[1], err)
+	}
+
+	var to int64
+	if len(args) > 2 {
+		to, err = strconv.ParseInt(args[2], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid revision %q: %w", args[2], err)
+		}
+	}
+
+	diff, err := l.root.Client.DiffWorkflowRevisions(cmd.Context(), args[0], from, to)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("--- revision %d\n+++ revision %d\n", diff.From, diff.To)
+	fmt.Print(diff.Diff)
+	return nil
+}
+
+type WorkflowRollback struct {
+	root *Obot
+}
+
+func (l *WorkflowRollback) Customize(cmd *cobra.Command) {
+	cmd.Use = "rollback [flags] WORKFLOW_ID REVISION"
+	cmd.Args = cobra.ExactArgs(2)
+}
+
+func (l *WorkflowRollback) Run(cmd *cobra.Command, args []string) error {
+	revision, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid revision %q: %w", args[1], err)
+	}
+
+	if _, err := l.root.Client.RollbackWorkflow(cmd.Context(), args[0], revision); err != nil {
+		return err
+	}
+
+	fmt.Printf("Rolled back workflow %s to revision %d\n", args[0], revision)
+	return nil
+}
@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/obot-platform/obot/apiclient/types"
+	"github.com/spf13/cobra"
+)
+
+type WorkflowValidate struct {
+	root *Obot
+}
+
+func (l *WorkflowValidate) Customize(cmd *cobra.Command) {
+	cmd.Use = "validate [flags] FILE"
+	cmd.Args = cobra.ExactArgs(1)
+}
+
+func (l *WorkflowValidate) Run(cmd *cobra.Command, args []string) error {
+	input, err := readInput(args[0])
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+
+	data, err := io.ReadAll(input)
+	if err != nil {
+		return err
+	}
+
+	manifests, err := parseManifests(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse manifest: %v", err)
+	}
+
+	var workflows, problems int
+	for _, manifest := range manifests {
+		if !strings.EqualFold(manifest.Type, "workflow") {
+			continue
+		}
+		workflows++
+
+		var workflow types.WorkflowManifest
+		if err := json.Unmarshal(manifest.Data, &workflow); err != nil {
+			return fmt.Errorf("failed to parse workflow: %v", err)
+		}
+
+		result, err := l.root.Client.ValidateWorkflow(cmd.Context(), workflow)
+		if err != nil {
+			return err
+		}
+
+		for _, problem := range result.Problems {
+			prefix := workflow.Name
+			if problem.Path != "" {
+				prefix += " " + problem.Path
+			}
+			fmt.Printf("%s: %s\n", strings.TrimSpace(prefix), problem.Message)
+		}
+		problems += len(result.Problems)
+	}
+
+	switch {
+	case workflows == 0:
+		return fmt.Errorf("no workflow found in %s", args[0])
+	case problems > 0:
+		return fmt.Errorf("found %d problem(s) in %s", problems, args[0])
+	}
+
+	fmt.Printf("%s is valid\n", args[0])
+	return nil
+}
@@ -2,19 +2,29 @@ package cronjob
 
 import (
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/adhocore/gronx"
 	"github.com/obot-platform/nah/pkg/router"
 	"github.com/obot-platform/obot/apiclient/types"
+	"github.com/obot-platform/obot/logger"
 	"github.com/obot-platform/obot/pkg/alias"
+	"github.com/obot-platform/obot/pkg/hash"
 	v1 "github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1"
 	"github.com/obot-platform/obot/pkg/system"
+	"github.com/obot-platform/obot/pkg/workflowparams"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	kclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// nextRunsPreview is the number of upcoming scheduled times kept in the status of a cron job.
+const nextRunsPreview = 5
+
+var log = logger.Package()
+
 type Handler struct{}
 
 func New() *Handler {
@@ -41,50 +51,207 @@ func GetSchedule(cronJob v1.CronJob) string {
 	return cronJob.Spec.Schedule
 }
 
+// Location returns the location of the timezone of a cron job. Cron jobs without a timezone run in the timezone of
+// the server.
+func Location(timezone string) (*time.Location, error) {
+	if timezone == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %w", timezone, err)
+	}
+	return loc, nil
+}
+
+// NextRun returns the first time after the given time that the schedule of the cron job is due in its timezone. The
+// schedule is matched against the wall clock of the timezone, so a time that is skipped when the clocks go forward
+// runs right after the change, shifted by the length of the gap, and a time that repeats when the clocks go back
+// runs only once.
+func NextRun(cronJob v1.CronJob, after time.Time) (time.Time, error) {
+	loc, err := Location(cronJob.Spec.Timezone)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	schedule := GetSchedule(cronJob)
+	wall := wallClock(after.In(loc))
+	for {
+		next, err := gronx.NextTickAfter(schedule, wall, false)
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		t := time.Date(next.Year(), next.Month(), next.Day(), next.Hour(), next.Minute(), next.Second(), 0, loc)
+		if skipped := next.Sub(wallClock(t)); skipped > 0 {
+			t = t.Add(skipped)
+		}
+		if t.After(after) {
+			return t, nil
+		}
+
+		// The wall clock time repeats and its first occurrence has passed already.
+		wall = next
+	}
+}
+
+// NextRuns returns the next n times after the given time that the schedule of the cron job is due in its timezone.
+func NextRuns(cronJob v1.CronJob, after time.Time, n int) ([]time.Time, error) {
+	runs := make([]time.Time, 0, n)
+	for range n {
+		next, err := NextRun(cronJob, after)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, next)
+		after = next
+	}
+	return runs, nil
+}
+
+// wallClock returns the date and time shown by the clock of the location of t as a time in UTC, which has no
+// daylight saving changes.
+func wallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
+
+// Run starts the runs of the cron job that are due. Every scheduled time since the last one that was handled is
+// either started or skipped, depending on the catch up setting, the starting deadline and the concurrency policy of
+// the cron job. Nothing is handled while the cron job is suspended, and resuming it moves its last scheduled time to
+// the time it was resumed, so the times scheduled in the meantime are never started.
 func (h *Handler) Run(req router.Request, resp router.Response) error {
 	cj := req.Object.(*v1.CronJob)
-	lastRun := cj.Status.LastRunStartedAt
-	if lastRun.IsZero() {
-		lastRun = &cj.CreationTimestamp
+	if cj.Spec.Suspend {
+		// Resuming the cron job updates it, which handles it again.
+		cj.Status.NextRuns = nil
+		return nil
 	}
 
-	next, err := gronx.NextTickAfter(GetSchedule(*cj), lastRun.Time, false)
+	from := cj.Status.LastScheduledTime
+	if from.IsZero() {
+		from = cj.Status.LastRunStartedAt
+	}
+	if from.IsZero() {
+		from = &cj.CreationTimestamp
+	}
+
+	now := time.Now()
+	scheduled, more, err := dueTimes(*cj, from.Time, now)
 	if err != nil {
 		return fmt.Errorf("failed to parse schedule: %w", err)
 	}
 
-	if until := time.Until(next); until > 0 {
-		resp.RetryAfter(until)
-		return nil
+	if len(scheduled) > 0 {
+		if err := h.runDue(req, cj, scheduled, more, now); err != nil {
+			return err
+		}
+		from = cj.Status.LastScheduledTime
+	}
+
+	next, err := NextRun(*cj, from.Time)
+	if err != nil {
+		return fmt.Errorf("failed to parse schedule: %w", err)
 	}
 
+	upcoming, err := NextRuns(*cj, now, nextRunsPreview)
+	if err != nil {
+		return fmt.Errorf("failed to parse schedule: %w", err)
+	}
+	cj.Status.NextRuns = make([]metav1.Time, 0, len(upcoming))
+	for _, t := range upcoming {
+		cj.Status.NextRuns = append(cj.Status.NextRuns, metav1.NewTime(t))
+	}
+
+	resp.RetryAfter(max(time.Until(next), time.Second))
+	return nil
+}
+
+func (h *Handler) runDue(req router.Request, cj *v1.CronJob, scheduled []time.Time, more bool, now time.Time) error {
 	var workflow v1.Workflow
 	if err := alias.Get(req.Ctx, req.Client, &workflow, cj.Namespace, cj.Spec.Workflow); err != nil {
 		return err
 	}
 
-	if err = req.Client.Create(req.Ctx,
-		&v1.WorkflowExecution{
-			ObjectMeta: metav1.ObjectMeta{
-				GenerateName: system.WorkflowExecutionPrefix,
-				Namespace:    req.Namespace,
-			},
-			Spec: v1.WorkflowExecutionSpec{
-				WorkflowName: workflow.Name,
-				Input:        cj.Spec.Input,
-				CronJobName:  cj.Name,
-				ThreadName:   cj.Spec.ThreadName,
-			},
-		},
-	); err != nil {
+	active, err := activeExecutions(req, cj)
+	if err != nil {
 		return err
 	}
 
-	cj.Status.LastRunStartedAt = &[]metav1.Time{metav1.Now()}[0]
+	input, inputErr := workflowparams.Validate(workflow.Spec.Manifest.InputParams, cj.Spec.Input)
+
+	for i, scheduledTime := range scheduled {
+		cj.Status.LastScheduledTime = &metav1.Time{Time: scheduledTime}
+
+		if reason := skipReason(*cj, scheduledTime, now, i == len(scheduled)-1 && !more); reason != "" {
+			recordSkipped(cj, scheduledTime, "", reason)
+			continue
+		}
+
+		if inputErr != nil {
+			// Skip this run, the input won't become valid by retrying.
+			log.Errorf("Skipping run of cronjob %s/%s: %v", cj.Namespace, cj.Name, inputErr)
+			recordSkipped(cj, scheduledTime, "", fmt.Sprintf("invalid input: %v", inputErr))
+			continue
+		}
+
+		if active, err = h.start(req, cj, &workflow, input, scheduledTime, active); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
 
+// start creates the execution of the run scheduled at the given time. The name of the execution is derived from the
+// cron job and the scheduled time, so a time that is handled again, because the status of the cron job couldn't be
+// updated after the run was started, doesn't start a second run.
+func (h *Handler) start(req router.Request, cj *v1.CronJob, workflow *v1.Workflow, input string, scheduledTime time.Time, active []v1.WorkflowExecution) ([]v1.WorkflowExecution, error) {
+	name := system.WorkflowExecutionPrefix + hash.String([]string{cj.Namespace, cj.Name, scheduledTime.UTC().Format(time.RFC3339)})[:16]
+	if slices.ContainsFunc(active, func(wfe v1.WorkflowExecution) bool {
+		return wfe.Name == name
+	}) {
+		return active, nil
+	}
+
+	switch cj.Spec.ConcurrencyPolicy {
+	case ConcurrencyPolicyForbid:
+		if len(active) > 0 {
+			recordSkipped(cj, scheduledTime, "", fmt.Sprintf("execution %s of the previous run is still running", active[0].Name))
+			return active, nil
+		}
+	case ConcurrencyPolicyReplace:
+		for _, wfe := range active {
+			if err := cancelExecution(req, &wfe); err != nil {
+				return nil, err
+			}
+			recordSkipped(cj, scheduledTime, wfe.Name, fmt.Sprintf("replaced by the run scheduled at %s", scheduledTime.UTC().Format(time.RFC3339)))
+		}
+		active = nil
+	}
+
+	wfe := v1.WorkflowExecution{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: req.Namespace,
+		},
+		Spec: v1.WorkflowExecutionSpec{
+			WorkflowName: workflow.Name,
+			Input:        input,
+			CronJobName:  cj.Name,
+			ThreadName:   cj.Spec.ThreadName,
+		},
+	}
+	if err := req.Client.Create(req.Ctx, &wfe); apierrors.IsAlreadyExists(err) {
+		// The run was started before and has finished already.
+		return active, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	cj.Status.LastRunStartedAt = &[]metav1.Time{metav1.Now()}[0]
+	return append(active, wfe), nil
+}
+
 func (h *Handler) SetSuccessRunTime(req router.Request, _ router.Response) error {
 	cj := req.Object.(*v1.CronJob)
 
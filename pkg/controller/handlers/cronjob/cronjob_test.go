@@ -0,0 +1,103 @@
+package cronjob
+
+import (
+	"testing"
+	"time"
+
+	"github.com/obot-platform/obot/apiclient/types"
+	v1 "github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1"
+)
+
+func TestNextRun(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule string
+		timezone string
+		after    time.Time
+		want     time.Time
+		wantErr  bool
+	}{
+		{
+			name:     "time skipped when the clocks go forward runs after the change",
+			schedule: "30 2 * * *",
+			timezone: "America/New_York",
+			after:    time.Date(2024, 3, 10, 5, 0, 0, 0, time.UTC),
+			want:     time.Date(2024, 3, 10, 7, 30, 0, 0, time.UTC),
+		},
+		{
+			name:     "day after the clocks go forward",
+			schedule: "30 2 * * *",
+			timezone: "America/New_York",
+			after:    time.Date(2024, 3, 10, 7, 30, 0, 0, time.UTC),
+			want:     time.Date(2024, 3, 11, 6, 30, 0, 0, time.UTC),
+		},
+		{
+			name:     "time repeated when the clocks go back runs at the first occurrence",
+			schedule: "30 1 * * *",
+			timezone: "America/New_York",
+			after:    time.Date(2024, 11, 3, 4, 0, 0, 0, time.UTC),
+			want:     time.Date(2024, 11, 3, 5, 30, 0, 0, time.UTC),
+		},
+		{
+			name:     "time repeated when the clocks go back runs only once",
+			schedule: "30 1 * * *",
+			timezone: "America/New_York",
+			after:    time.Date(2024, 11, 3, 5, 30, 0, 0, time.UTC),
+			want:     time.Date(2024, 11, 4, 6, 30, 0, 0, time.UTC),
+		},
+		{
+			name:     "timezone with a half hour offset",
+			schedule: "0 9 * * *",
+			timezone: "Asia/Kolkata",
+			after:    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			want:     time.Date(2024, 1, 1, 3, 30, 0, 0, time.UTC),
+		},
+		{
+			name:     "timezone with a quarter hour offset",
+			schedule: "0 * * * *",
+			timezone: "Asia/Kathmandu",
+			after:    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			want:     time.Date(2024, 1, 1, 0, 15, 0, 0, time.UTC),
+		},
+		{
+			name:     "empty timezone is the timezone of the server",
+			schedule: "0 12 * * *",
+			after:    time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local),
+			want:     time.Date(2024, 1, 1, 12, 0, 0, 0, time.Local),
+		},
+		{
+			name:     "invalid timezone",
+			schedule: "0 12 * * *",
+			timezone: "Mars/Olympus_Mons",
+			after:    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cronJob := v1.CronJob{
+				Spec: v1.CronJobSpec{
+					CronJobManifest: types.CronJobManifest{
+						Schedule: tt.schedule,
+						Timezone: tt.timezone,
+					},
+				},
+			}
+
+			got, err := NextRun(cronJob, tt.after)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("NextRun() = %v, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NextRun() error = %v", err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("NextRun() = %v, want %v", got.UTC(), tt.want.UTC())
+			}
+		})
+	}
+}
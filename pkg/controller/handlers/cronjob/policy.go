@@ -0,0 +1,139 @@
+package cronjob
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/adhocore/gronx"
+	"github.com/obot-platform/nah/pkg/router"
+	"github.com/obot-platform/obot/apiclient/types"
+	v1 "github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	ConcurrencyPolicyAllow   = "Allow"
+	ConcurrencyPolicyForbid  = "Forbid"
+	ConcurrencyPolicyReplace = "Replace"
+
+	CatchUpAll    = "all"
+	CatchUpLatest = "latest"
+	CatchUpNone   = "none"
+
+	// missedRunGrace is how late a scheduled time can be handled before it counts as missed.
+	missedRunGrace = time.Minute
+	// maxDueTimes limits the scheduled times handled at once, the rest are handled right after.
+	maxDueTimes = 100
+	// maxSkippedRuns is the number of skipped runs kept in the status of a cron job.
+	maxSkippedRuns = 20
+)
+
+// Validate checks the schedule, timezone, concurrency policy, starting deadline and catch up setting of a cron job.
+func Validate(manifest types.CronJobManifest) error {
+	if !gronx.IsValid(manifest.Schedule) {
+		return fmt.Errorf("invalid schedule %s", manifest.Schedule)
+	}
+	if _, err := Location(manifest.Timezone); err != nil {
+		return err
+	}
+	switch manifest.ConcurrencyPolicy {
+	case "", ConcurrencyPolicyAllow, ConcurrencyPolicyForbid, ConcurrencyPolicyReplace:
+	default:
+		return fmt.Errorf("invalid concurrency policy %q, must be one of %s, %s, %s", manifest.ConcurrencyPolicy,
+			ConcurrencyPolicyAllow, ConcurrencyPolicyForbid, ConcurrencyPolicyReplace)
+	}
+	if manifest.StartingDeadlineSeconds != nil && *manifest.StartingDeadlineSeconds < 0 {
+		return fmt.Errorf("startingDeadlineSeconds must not be negative")
+	}
+	switch manifest.CatchUp {
+	case "", CatchUpAll, CatchUpLatest, CatchUpNone:
+	default:
+		return fmt.Errorf("invalid catch up %q, must be one of %s, %s, %s", manifest.CatchUp,
+			CatchUpAll, CatchUpLatest, CatchUpNone)
+	}
+	return nil
+}
+
+// dueTimes returns the scheduled times of the cron job after the given time up to now, oldest first, and whether
+// more are due than were returned.
+func dueTimes(cronJob v1.CronJob, after, now time.Time) ([]time.Time, bool, error) {
+	var scheduled []time.Time
+	for {
+		next, err := NextRun(cronJob, after)
+		if err != nil {
+			return nil, false, err
+		}
+		if next.After(now) {
+			return scheduled, false, nil
+		}
+		if len(scheduled) == maxDueTimes {
+			return scheduled, true, nil
+		}
+		scheduled = append(scheduled, next)
+		after = next
+	}
+}
+
+// skipReason returns why the run scheduled at the given time is skipped, or an empty string if it starts. A run that
+// is handled later than its starting deadline is skipped. A run that is handled more than a minute late, because the
+// server was down, is missed and only started if the catch up setting asks for it. By default, only the latest
+// missed run is caught up.
+func skipReason(cronJob v1.CronJob, scheduledTime, now time.Time, latest bool) string {
+	late := now.Sub(scheduledTime)
+	if deadline := cronJob.Spec.StartingDeadlineSeconds; deadline != nil && late > time.Duration(*deadline)*time.Second {
+		return fmt.Sprintf("missed the starting deadline of %ds", *deadline)
+	}
+	if late <= missedRunGrace {
+		return ""
+	}
+
+	switch cronJob.Spec.CatchUp {
+	case CatchUpAll:
+		return ""
+	case CatchUpNone:
+		return "missed, missed runs are not caught up"
+	default:
+		if !latest {
+			return "missed, only the latest missed run is caught up"
+		}
+		return ""
+	}
+}
+
+func recordSkipped(cronJob *v1.CronJob, scheduledTime time.Time, workflowExecutionName, reason string) {
+	cronJob.Status.SkippedRuns = append(cronJob.Status.SkippedRuns, v1.CronJobSkippedRun{
+		ScheduledTime:         metav1.NewTime(scheduledTime),
+		WorkflowExecutionName: workflowExecutionName,
+		Reason:                reason,
+	})
+	if extra := len(cronJob.Status.SkippedRuns) - maxSkippedRuns; extra > 0 {
+		cronJob.Status.SkippedRuns = cronJob.Status.SkippedRuns[extra:]
+	}
+}
+
+// activeExecutions returns the executions started by the cron job that haven't finished yet.
+func activeExecutions(req router.Request, cronJob *v1.CronJob) ([]v1.WorkflowExecution, error) {
+	var wfes v1.WorkflowExecutionList
+	if err := req.List(&wfes, &kclient.ListOptions{
+		Namespace:     cronJob.Namespace,
+		FieldSelector: fields.SelectorFromSet(map[string]string{"spec.cronJobName": cronJob.Name}),
+	}); err != nil {
+		return nil, err
+	}
+
+	var active []v1.WorkflowExecution
+	for _, wfe := range wfes.Items {
+		if !wfe.Spec.Cancel && !wfe.Status.State.IsTerminal() && wfe.Status.State != types.WorkflowStateCancelled {
+			active = append(active, wfe)
+		}
+	}
+	return active, nil
+}
+
+func cancelExecution(req router.Request, wfe *v1.WorkflowExecution) error {
+	wfe.Spec.Cancel = true
+	wfe.Spec.CancelledBy = "cronjob"
+	return kclient.IgnoreNotFound(req.Client.Update(req.Ctx, wfe))
+}
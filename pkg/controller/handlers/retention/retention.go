@@ -0,0 +1,292 @@
+package retention
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/obot-platform/nah/pkg/router"
+	"github.com/obot-platform/obot/apiclient/types"
+	"github.com/obot-platform/obot/logger"
+	"github.com/obot-platform/obot/pkg/controller/handlers/webhook"
+	ktime "github.com/obot-platform/obot/pkg/gateway/time"
+	v1 "github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const gcInterval = 10 * time.Minute
+
+var log = logger.Package()
+
+type Handler struct {
+	defaults types.WorkflowRetention
+}
+
+// New returns a handler that garbage collects the executions of workflows. The defaults apply to every workflow,
+// and the retention of a workflow overrides the fields it sets.
+func New(defaults types.WorkflowRetention) *Handler {
+	return &Handler{
+		defaults: defaults,
+	}
+}
+
+// Validate checks the retention of a workflow manifest or the server defaults.
+func Validate(retention *types.WorkflowRetention) error {
+	if retention == nil {
+		return nil
+	}
+	_, err := parse(*retention)
+	return err
+}
+
+// Effective returns the retention of the workflow with the fields it doesn't set taken from the defaults.
+func Effective(manifest types.WorkflowManifest, defaults types.WorkflowRetention) types.WorkflowRetention {
+	retention := defaults
+	if manifest.Retention == nil {
+		return retention
+	}
+	if manifest.Retention.KeepLast > 0 {
+		retention.KeepLast = manifest.Retention.KeepLast
+	}
+	if manifest.Retention.MaxAge != "" {
+		retention.MaxAge = manifest.Retention.MaxAge
+	}
+	if manifest.Retention.FailedMaxAge != "" {
+		retention.FailedMaxAge = manifest.Retention.FailedMaxAge
+	}
+	return retention
+}
+
+type policy struct {
+	keepLast     int
+	maxAge       time.Duration
+	failedMaxAge time.Duration
+}
+
+func parse(retention types.WorkflowRetention) (policy, error) {
+	var (
+		p   = policy{keepLast: retention.KeepLast}
+		err error
+	)
+	if retention.KeepLast < 0 {
+		return p, fmt.Errorf("keepLast must not be negative")
+	}
+	if retention.MaxAge != "" {
+		if p.maxAge, err = ktime.ParseDuration(retention.MaxAge); err != nil {
+			return p, fmt.Errorf("invalid maxAge %q: %w", retention.MaxAge, err)
+		} else if p.maxAge < 0 {
+			return p, fmt.Errorf("invalid maxAge %q: must not be negative", retention.MaxAge)
+		}
+	}
+	if retention.FailedMaxAge != "" {
+		if p.failedMaxAge, err = ktime.ParseDuration(retention.FailedMaxAge); err != nil {
+			return p, fmt.Errorf("invalid failedMaxAge %q: %w", retention.FailedMaxAge, err)
+		} else if p.failedMaxAge < 0 {
+			return p, fmt.Errorf("invalid failedMaxAge %q: must not be negative", retention.FailedMaxAge)
+		}
+	}
+	return p, nil
+}
+
+// Expired is a workflow execution that the retention of its workflow no longer keeps.
+type Expired struct {
+	Execution v1.WorkflowExecution
+	Reason    string
+}
+
+// Plan returns the executions of the workflow that are garbage collected at now, newest first. Only finished
+// executions started by a trigger or a user are considered, subflows go with the execution that called them.
+// Executions older than maxAge are removed, and of the rest only the newest keepLast are kept. Failed executions are
+// kept until they are older than failedMaxAge instead, regardless of keepLast, so that they can be investigated.
+// Executions started by a webhook delivery with an idempotency key are kept while later deliveries with the same key
+// are deduplicated against them.
+func Plan(ctx context.Context, c kclient.Client, wf *v1.Workflow, defaults types.WorkflowRetention, now time.Time) ([]Expired, error) {
+	retention := Effective(wf.Spec.Manifest, defaults)
+	p, err := parse(retention)
+	if err != nil {
+		return nil, fmt.Errorf("invalid retention for workflow %s: %w", wf.Name, err)
+	}
+	if p.keepLast == 0 && p.maxAge == 0 && p.failedMaxAge == 0 {
+		return nil, nil
+	}
+
+	var wfes v1.WorkflowExecutionList
+	if err := c.List(ctx, &wfes, kclient.InNamespace(wf.Namespace), kclient.MatchingFields{
+		"spec.workflowName": wf.Name,
+	}); err != nil {
+		return nil, err
+	}
+
+	var finished []v1.WorkflowExecution
+	for _, wfe := range wfes.Items {
+		if wfe.Spec.ParentThreadName == "" && wfe.DeletionTimestamp.IsZero() &&
+			(wfe.Status.State.IsTerminal() || wfe.Status.State == types.WorkflowStateCancelled) {
+			finished = append(finished, wfe)
+		}
+	}
+	slices.SortFunc(finished, func(a, b v1.WorkflowExecution) int {
+		return endTime(b).Compare(endTime(a))
+	})
+
+	windows, err := deliveryWindows(ctx, c, wf.Namespace, finished)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		expired []Expired
+		kept    int
+	)
+	for _, wfe := range finished {
+		if window, ok := windows[wfe.Spec.WebhookName]; ok && wfe.Spec.WebhookDeliveryKey != "" &&
+			now.Sub(wfe.CreationTimestamp.Time) < window {
+			// The idempotency window of the delivery hasn't passed yet.
+			continue
+		}
+
+		var (
+			age       = now.Sub(endTime(wfe))
+			maxAge    = p.maxAge
+			reason    = fmt.Sprintf("finished more than %s ago", retention.MaxAge)
+			protected bool
+		)
+		if wfe.Status.State == types.WorkflowStateError && p.failedMaxAge > 0 {
+			maxAge, protected = p.failedMaxAge, true
+			reason = fmt.Sprintf("failed more than %s ago", retention.FailedMaxAge)
+		}
+
+		switch {
+		case maxAge > 0 && age > maxAge:
+			expired = append(expired, Expired{Execution: wfe, Reason: reason})
+		case protected:
+		case p.keepLast > 0 && kept >= p.keepLast:
+			expired = append(expired, Expired{Execution: wfe, Reason: fmt.Sprintf("not one of the last %d executions", p.keepLast)})
+		default:
+			kept++
+		}
+	}
+
+	return expired, nil
+}
+
+// deliveryWindows returns the idempotency windows of the webhooks that started the executions by webhook name.
+func deliveryWindows(ctx context.Context, c kclient.Client, namespace string, wfes []v1.WorkflowExecution) (map[string]time.Duration, error) {
+	windows := map[string]time.Duration{}
+	for _, wfe := range wfes {
+		if wfe.Spec.WebhookDeliveryKey == "" {
+			continue
+		}
+		if _, ok := windows[wfe.Spec.WebhookName]; ok {
+			continue
+		}
+
+		var wh v1.Webhook
+		if err := c.Get(ctx, router.Key(namespace, wfe.Spec.WebhookName), &wh); apierrors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		window, err := webhook.IdempotencyWindow(wh.Spec.Idempotency)
+		if err != nil {
+			window = webhook.DefaultIdempotencyWindow
+		}
+		windows[wfe.Spec.WebhookName] = window
+	}
+	return windows, nil
+}
+
+func endTime(wfe v1.WorkflowExecution) time.Time {
+	if wfe.Status.EndTime != nil {
+		return wfe.Status.EndTime.Time
+	}
+	return wfe.CreationTimestamp.Time
+}
+
+// GC deletes the executions of the workflow that its retention no longer keeps. Executions aren't watched and age
+// without changing, so the workflow is checked again periodically.
+func (h *Handler) GC(req router.Request, resp router.Response) error {
+	wf := req.Object.(*v1.Workflow)
+
+	expired, err := Plan(req.Ctx, req.Client, wf, h.defaults, time.Now())
+	if err != nil {
+		log.Errorf("Skipping garbage collection of executions: %v", err)
+		return nil
+	}
+
+	for _, e := range expired {
+		log.Infof("Deleting workflow execution %s/%s of workflow %s: %s", e.Execution.Namespace, e.Execution.Name, wf.Name, e.Reason)
+		if err := Delete(req.Ctx, req.Client, &e.Execution); err != nil {
+			return err
+		}
+	}
+
+	if retention := Effective(wf.Spec.Manifest, h.defaults); retention != (types.WorkflowRetention{}) {
+		resp.RetryAfter(gcInterval)
+	}
+	return nil
+}
+
+// Delete deletes a workflow execution with everything it left behind: its steps, the executions of its subflows,
+// and its thread with the runs and the workspace of the thread. The execution is deleted last, so that an
+// interrupted delete is picked up again by the next collection.
+func Delete(ctx context.Context, c kclient.Client, wfe *v1.WorkflowExecution) error {
+	var steps v1.WorkflowStepList
+	if err := c.List(ctx, &steps, kclient.InNamespace(wfe.Namespace), kclient.MatchingFields{
+		"spec.workflowExecutionName": wfe.Name,
+	}); err != nil {
+		return err
+	}
+	for _, step := range steps.Items {
+		if err := kclient.IgnoreNotFound(c.Delete(ctx, &step)); err != nil {
+			return err
+		}
+	}
+
+	if wfe.Status.ThreadName != "" {
+		var subflows v1.WorkflowExecutionList
+		if err := c.List(ctx, &subflows, kclient.InNamespace(wfe.Namespace), kclient.MatchingFields{
+			"spec.parentThreadName": wfe.Status.ThreadName,
+		}); err != nil {
+			return err
+		}
+		for _, subflow := range subflows.Items {
+			if err := Delete(ctx, c, &subflow); err != nil {
+				return err
+			}
+		}
+
+		var runs v1.RunList
+		if err := c.List(ctx, &runs, kclient.InNamespace(wfe.Namespace), kclient.MatchingFields{
+			"spec.threadName": wfe.Status.ThreadName,
+		}); err != nil {
+			return err
+		}
+		for _, run := range runs.Items {
+			if err := kclient.IgnoreNotFound(c.Delete(ctx, &run)); err != nil {
+				return err
+			}
+		}
+
+		var thread v1.Thread
+		if err := c.Get(ctx, kclient.ObjectKey{Namespace: wfe.Namespace, Name: wfe.Status.ThreadName}, &thread); kclient.IgnoreNotFound(err) != nil {
+			return err
+		} else if err == nil {
+			if thread.Status.WorkspaceName != "" {
+				if err := kclient.IgnoreNotFound(c.Delete(ctx, &v1.Workspace{
+					ObjectMeta: metav1.ObjectMeta{Namespace: thread.Namespace, Name: thread.Status.WorkspaceName},
+				})); err != nil {
+					return err
+				}
+			}
+			if err := kclient.IgnoreNotFound(c.Delete(ctx, &thread)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return kclient.IgnoreNotFound(c.Delete(ctx, wfe))
+}
@@ -15,10 +15,12 @@ import (
 
 type Handler struct {
 	invoker *invoke.Invoker
+	// retention is how long finished runs of system tasks are kept.
+	retention time.Duration
 }
 
-func New(invoker *invoke.Invoker) *Handler {
-	return &Handler{invoker: invoker}
+func New(invoker *invoke.Invoker, retention time.Duration) *Handler {
+	return &Handler{invoker: invoker, retention: retention}
 }
 
 func (*Handler) DeleteRunState(req router.Request, _ router.Response) error {
@@ -72,7 +74,7 @@ func (h *Handler) Resume(req router.Request, _ router.Response) error {
 
 func (h *Handler) DeleteFinished(req router.Request, _ router.Response) error {
 	run := req.Object.(*v1.Run)
-	if run.Status.State == gptscript.Finished && time.Since(run.Status.EndTime.Time) > 12*time.Hour || (run.Spec.Synchronous && run.Status.State == "" && time.Since(run.CreationTimestamp.Time) > 12*time.Hour) {
+	if run.Status.State == gptscript.Finished && time.Since(run.Status.EndTime.Time) > h.retention || (run.Spec.Synchronous && run.Status.State == "" && time.Since(run.CreationTimestamp.Time) > h.retention) {
 		// These will be system tasks. Everything is a chat and finished with Continue status
 		return req.Delete(run)
 	}
@@ -1,13 +1,35 @@
 package webhook
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/obot-platform/nah/pkg/router"
 	"github.com/obot-platform/obot/apiclient/types"
+	ktime "github.com/obot-platform/obot/pkg/gateway/time"
 	v1 "github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	kclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// DefaultIdempotencyWindow is how long a delivery with the same idempotency key is a duplicate by default.
+const DefaultIdempotencyWindow = 24 * time.Hour
+
+// IdempotencyWindow returns how long a delivery with the same idempotency key as an earlier one is a duplicate.
+func IdempotencyWindow(idempotency *types.WebhookIdempotency) (time.Duration, error) {
+	if idempotency == nil || idempotency.Window == "" {
+		return DefaultIdempotencyWindow, nil
+	}
+	window, err := ktime.ParseDuration(idempotency.Window)
+	if err != nil {
+		return 0, fmt.Errorf("invalid idempotency window %q: %w", idempotency.Window, err)
+	}
+	if window <= 0 {
+		return 0, fmt.Errorf("invalid idempotency window %q: must be positive", idempotency.Window)
+	}
+	return window, nil
+}
+
 type Handler struct{}
 
 func New() *Handler {
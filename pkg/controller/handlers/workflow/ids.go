@@ -11,6 +11,9 @@ func PopulateIDs(manifest types.WorkflowManifest) types.WorkflowManifest {
 	for i, step := range manifest.Steps {
 		manifest.Steps[i] = populateStepID(ids, step)
 	}
+	if manifest.OnError != nil {
+		manifest.OnError = populateOnErrorID(ids, *manifest.OnError)
+	}
 	return manifest
 }
 
@@ -41,6 +44,18 @@ func populateStepID(seen map[string]struct{}, step types.Step) types.Step {
 	if step.If != nil {
 		step.If = populateIfID(seen, *step.If)
 	}
+	if step.Parallel != nil {
+		step.Parallel = populateParallelID(seen, *step.Parallel)
+	}
+	if step.ForEach != nil {
+		step.ForEach = populateForEachID(seen, *step.ForEach)
+	}
+	if step.Switch != nil {
+		step.Switch = populateSwitchID(seen, *step.Switch)
+	}
+	if step.OnError != nil {
+		step.OnError = populateOnErrorID(seen, *step.OnError)
+	}
 	return step
 }
 
@@ -60,3 +75,38 @@ func populateIfID(seen map[string]struct{}, ifStep types.If) *types.If {
 	}
 	return &ifStep
 }
+
+func populateParallelID(seen map[string]struct{}, parallel types.Parallel) *types.Parallel {
+	for i, branch := range parallel.Branches {
+		for j, step := range branch.Steps {
+			parallel.Branches[i].Steps[j] = populateStepID(seen, step)
+		}
+	}
+	return &parallel
+}
+
+func populateForEachID(seen map[string]struct{}, forEach types.ForEach) *types.ForEach {
+	for i, step := range forEach.Steps {
+		forEach.Steps[i] = populateStepID(seen, step)
+	}
+	return &forEach
+}
+
+func populateSwitchID(seen map[string]struct{}, switchStep types.Switch) *types.Switch {
+	for i, c := range switchStep.Cases {
+		for j, step := range c.Steps {
+			switchStep.Cases[i].Steps[j] = populateStepID(seen, step)
+		}
+	}
+	for i, step := range switchStep.Default {
+		switchStep.Default[i] = populateStepID(seen, step)
+	}
+	return &switchStep
+}
+
+func populateOnErrorID(seen map[string]struct{}, onError types.OnError) *types.OnError {
+	for i, step := range onError.Steps {
+		onError.Steps[i] = populateStepID(seen, step)
+	}
+	return &onError
+}
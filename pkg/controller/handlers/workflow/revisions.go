@@ -0,0 +1,64 @@
+package workflow
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/obot-platform/nah/pkg/name"
+	"github.com/obot-platform/nah/pkg/router"
+	v1 "github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1"
+	"github.com/obot-platform/obot/pkg/system"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RevisionName returns the name of the given revision of a workflow.
+func RevisionName(workflowName string, revision int64) string {
+	return name.SafeConcatName(system.WorkflowRevisionPrefix+strings.TrimPrefix(workflowName, system.WorkflowPrefix), strconv.FormatInt(revision, 10))
+}
+
+// CreateRevision stores the manifest of the workflow as a new revision every time it changes. Revisions are never
+// updated, so executions can keep running the revision they started with while the workflow is edited.
+func CreateRevision(req router.Request, _ router.Response) error {
+	wf := req.Object.(*v1.Workflow)
+
+	if wf.Status.Revision > 0 && wf.Status.RevisionGeneration == wf.Generation {
+		return nil
+	}
+
+	if !equality.Semantic.DeepEqual(wf.Spec.Manifest, PopulateIDs(wf.Spec.Manifest)) {
+		// Wait for the IDs of the steps to be populated.
+		return nil
+	}
+
+	if wf.Status.Revision > 0 {
+		var latest v1.WorkflowRevision
+		if err := req.Get(&latest, wf.Namespace, RevisionName(wf.Name, wf.Status.Revision)); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		} else if err == nil && equality.Semantic.DeepEqual(latest.Spec.Manifest, wf.Spec.Manifest) {
+			// Only fields outside the manifest changed.
+			wf.Status.RevisionGeneration = wf.Generation
+			return nil
+		}
+	}
+
+	revision := wf.Status.Revision + 1
+	if err := req.Client.Create(req.Ctx, &v1.WorkflowRevision{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      RevisionName(wf.Name, revision),
+			Namespace: wf.Namespace,
+		},
+		Spec: v1.WorkflowRevisionSpec{
+			WorkflowName: wf.Name,
+			Revision:     revision,
+			Manifest:     wf.Spec.Manifest,
+		},
+	}); err != nil && !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+
+	wf.Status.Revision = revision
+	wf.Status.RevisionGeneration = wf.Generation
+	return nil
+}
@@ -0,0 +1,149 @@
+package workflowexecution
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/obot-platform/nah/pkg/router"
+	"github.com/obot-platform/obot/apiclient/types"
+	v1 "github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	budgetRecheckInterval = 10 * time.Second
+
+	// cancelledByBudget is who cancels an execution that exceeds its budget.
+	cancelledByBudget = "budget"
+)
+
+// ValidateBudget checks the budget of a workflow manifest or execution.
+func ValidateBudget(budget *types.WorkflowBudget) error {
+	if budget == nil {
+		return nil
+	}
+	if budget.MaxTokens < 0 || budget.MaxCost < 0 {
+		return fmt.Errorf("budget limits must not be negative")
+	}
+	if budget.PromptTokenPrice < 0 || budget.CompletionTokenPrice < 0 {
+		return fmt.Errorf("token prices must not be negative")
+	}
+	if budget.MaxCost > 0 && budget.PromptTokenPrice == 0 && budget.CompletionTokenPrice == 0 {
+		return fmt.Errorf("maxCost requires promptTokenPrice or completionTokenPrice to estimate the cost")
+	}
+	return nil
+}
+
+// Budget returns the budget of the execution, which is the budget of its workflow with the limits set on the execution
+// applied on top. It returns nil if the execution has no limits.
+func Budget(we *v1.WorkflowExecution) *types.WorkflowBudget {
+	var budget types.WorkflowBudget
+	if we.Status.WorkflowManifest != nil && we.Status.WorkflowManifest.Budget != nil {
+		budget = *we.Status.WorkflowManifest.Budget
+	}
+	if override := we.Spec.Budget; override != nil {
+		if override.MaxTokens > 0 {
+			budget.MaxTokens = override.MaxTokens
+		}
+		if override.MaxCost > 0 {
+			budget.MaxCost = override.MaxCost
+		}
+		if override.PromptTokenPrice > 0 {
+			budget.PromptTokenPrice = override.PromptTokenPrice
+		}
+		if override.CompletionTokenPrice > 0 {
+			budget.CompletionTokenPrice = override.CompletionTokenPrice
+		}
+	}
+	if budget.MaxTokens <= 0 && budget.MaxCost <= 0 {
+		return nil
+	}
+	return &budget
+}
+
+// EstimateCost returns the cost of the usage at the token prices of the budget, which are per million tokens.
+func EstimateCost(budget *types.WorkflowBudget, usage v1.TokenUsage) float64 {
+	if budget == nil {
+		return 0
+	}
+	return (float64(usage.PromptTokens)*budget.PromptTokenPrice + float64(usage.CompletionTokens)*budget.CompletionTokenPrice) / 1_000_000
+}
+
+// checkBudget records the tokens used by the runs of the execution and its subflows, and cancels the execution once it
+// exceeds its budget. Reruns count against the same budget. The runs aren't watched, so the usage of a running
+// execution with a budget is checked again periodically.
+func (h *Handler) checkBudget(req router.Request, resp router.Response, we *v1.WorkflowExecution) (bool, error) {
+	usage, err := h.usage(req, we)
+	if err != nil {
+		return false, err
+	}
+	we.Status.Usage = usage
+
+	if budgetExceeded(we) == "" {
+		if Budget(we) != nil {
+			resp.RetryAfter(budgetRecheckInterval)
+		}
+		return false, nil
+	}
+
+	// The usage is saved before the execution is cancelled, which then reports the exceeded budget as its error.
+	if err := req.Client.Status().Update(req.Ctx, we); err != nil {
+		return false, err
+	}
+	we.Spec.Cancel = true
+	we.Spec.CancelledBy = cancelledByBudget
+	return true, req.Client.Update(req.Ctx, we)
+}
+
+// budgetExceeded returns a message describing how the execution exceeded its budget, or an empty string if it didn't.
+func budgetExceeded(we *v1.WorkflowExecution) string {
+	budget := Budget(we)
+	if budget == nil {
+		return ""
+	}
+
+	usage := we.Status.Usage
+	if budget.MaxTokens > 0 && usage.TotalTokens > int64(budget.MaxTokens) {
+		return fmt.Sprintf("budget exceeded: used %d tokens of %d", usage.TotalTokens, budget.MaxTokens)
+	} else if cost := EstimateCost(budget, usage); budget.MaxCost > 0 && cost > budget.MaxCost {
+		return fmt.Sprintf("budget exceeded: used an estimated $%.4f of $%.4f", cost, budget.MaxCost)
+	}
+	return ""
+}
+
+// usage returns the tokens used by the runs of the execution and its subflows. The usage of every run and subflow is
+// recorded in the status, so the runs and subflows that reruns delete still count.
+func (h *Handler) usage(req router.Request, we *v1.WorkflowExecution) (usage v1.TokenUsage, _ error) {
+	if we.Status.ThreadName == "" {
+		return we.Status.Usage, nil
+	}
+
+	var runs v1.RunList
+	if err := req.List(&runs, &kclient.ListOptions{
+		Namespace:     we.Namespace,
+		FieldSelector: fields.SelectorFromSet(map[string]string{"spec.threadName": we.Status.ThreadName}),
+	}); err != nil {
+		return usage, err
+	}
+
+	subflows, err := listSubflows(req, we)
+	if err != nil {
+		return usage, err
+	}
+
+	if we.Status.RunUsage == nil {
+		we.Status.RunUsage = map[string]v1.TokenUsage{}
+	}
+	for _, run := range runs.Items {
+		we.Status.RunUsage[string(run.UID)] = run.Status.Usage
+	}
+	for _, subflow := range subflows {
+		we.Status.RunUsage[string(subflow.UID)] = subflow.Status.Usage
+	}
+
+	for _, runUsage := range we.Status.RunUsage {
+		usage = usage.Add(runUsage)
+	}
+	return usage, nil
+}
@@ -0,0 +1,113 @@
+package workflowexecution
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/obot-platform/nah/pkg/router"
+	"github.com/obot-platform/obot/apiclient/types"
+	v1 "github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	ConcurrencyPolicyQueue        = "queue"
+	ConcurrencyPolicySkip         = "skip"
+	ConcurrencyPolicyCancelOldest = "cancelOldest"
+
+	queuedRecheckInterval = 5 * time.Second
+)
+
+// ValidateConcurrency checks the concurrency settings of a workflow manifest.
+func ValidateConcurrency(concurrency *types.WorkflowConcurrency) error {
+	if concurrency == nil {
+		return nil
+	}
+	if concurrency.Max < 0 {
+		return fmt.Errorf("concurrency max must not be negative")
+	}
+	switch concurrency.Policy {
+	case "", ConcurrencyPolicyQueue, ConcurrencyPolicySkip, ConcurrencyPolicyCancelOldest:
+		return nil
+	default:
+		return fmt.Errorf("invalid concurrency policy %q, must be one of %s, %s, %s", concurrency.Policy,
+			ConcurrencyPolicyQueue, ConcurrencyPolicySkip, ConcurrencyPolicyCancelOldest)
+	}
+}
+
+// admit checks the concurrency limit of the workflow before the execution starts. Executions over the limit are
+// queued, skipped or make room by cancelling the oldest running execution, depending on the policy. Executions are
+// admitted in the order they were created: every active execution that was created before this one or has already
+// started takes a slot, whatever its state, so that executions created at the same time agree on which of them start
+// even if they don't see each other's status yet. Subflows and reruns are not limited, because they would wait on
+// themselves.
+func (h *Handler) admit(req router.Request, resp router.Response, we *v1.WorkflowExecution) (bool, error) {
+	concurrency := we.Status.WorkflowManifest.Concurrency
+	if concurrency == nil || concurrency.Max <= 0 || we.Status.ThreadName != "" || we.Spec.ParentThreadName != "" {
+		return true, nil
+	}
+
+	var wfes v1.WorkflowExecutionList
+	if err := req.List(&wfes, &kclient.ListOptions{
+		Namespace: we.Namespace,
+		FieldSelector: fields.SelectorFromSet(map[string]string{
+			"spec.workflowName": we.Spec.WorkflowName,
+		}),
+	}); err != nil {
+		return false, err
+	}
+
+	var running, waitingBefore []v1.WorkflowExecution
+	for _, other := range wfes.Items {
+		switch {
+		case other.Name == we.Name || other.Spec.ParentThreadName != "" || other.Spec.Cancel ||
+			other.Status.State.IsTerminal() || other.Status.State == types.WorkflowStateCancelled:
+		case other.Status.ThreadName != "":
+			running = append(running, other)
+		case createdBefore(other, *we):
+			waitingBefore = append(waitingBefore, other)
+		}
+	}
+
+	if len(running)+len(waitingBefore) < concurrency.Max {
+		return true, nil
+	}
+
+	switch concurrency.Policy {
+	case ConcurrencyPolicySkip:
+		we.Status.State = types.WorkflowStateCancelled
+		we.Status.Error = fmt.Sprintf("skipped, workflow %s already has %d running executions", we.Spec.WorkflowName, len(running))
+		we.Status.WorkflowGeneration = we.Spec.WorkflowGeneration
+		we.Status.EndTime = &metav1.Time{Time: time.Now()}
+		return false, nil
+	case ConcurrencyPolicyCancelOldest:
+		if len(running) > 0 && len(waitingBefore) == 0 {
+			oldest := slices.MinFunc(running, func(a, b v1.WorkflowExecution) int {
+				if createdBefore(a, b) {
+					return -1
+				}
+				return 1
+			})
+			oldest.Spec.Cancel = true
+			oldest.Spec.CancelledBy = "concurrency policy"
+			if err := req.Client.Update(req.Ctx, &oldest); err != nil {
+				return false, err
+			}
+		}
+	}
+
+	// Wait for a slot, the list of executions isn't watched so check again periodically.
+	we.Status.State = types.WorkflowStateQueued
+	resp.RetryAfter(queuedRecheckInterval)
+	return false, nil
+}
+
+func createdBefore(a, b v1.WorkflowExecution) bool {
+	if a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return a.Name < b.Name
+	}
+	return a.CreationTimestamp.Before(&b.CreationTimestamp)
+}
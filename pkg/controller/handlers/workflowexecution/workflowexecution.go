@@ -5,13 +5,16 @@ import (
 	"time"
 
 	"github.com/obot-platform/nah/pkg/apply"
+	"github.com/obot-platform/nah/pkg/randomtoken"
 	"github.com/obot-platform/nah/pkg/router"
 	"github.com/obot-platform/obot/apiclient/types"
+	"github.com/obot-platform/obot/pkg/controller/handlers/workflow"
 	"github.com/obot-platform/obot/pkg/controller/handlers/workflowstep"
 	"github.com/obot-platform/obot/pkg/invoke"
 	v1 "github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1"
 	"github.com/obot-platform/obot/pkg/system"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	kclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -25,15 +28,23 @@ func New(invoker *invoke.Invoker) *Handler {
 	}
 }
 
-func (h *Handler) Run(req router.Request, _ router.Response) error {
+func (h *Handler) Run(req router.Request, resp router.Response) error {
 	var (
 		we = req.Object.(*v1.WorkflowExecution)
 	)
 
-	if we.Status.State.IsTerminal() {
+	if we.Spec.Cancel {
+		return h.cancel(req, we)
+	}
+
+	if we.Status.State.IsTerminal() || we.Status.State == types.WorkflowStateCancelled {
 		if we.Spec.WorkflowGeneration != we.Status.WorkflowGeneration {
 			we.Status.State = types.WorkflowStatePending
 			we.Status.EndTime = nil
+			we.Status.OnError = nil
+			we.Status.StructuredOutput = ""
+			// The steps wait for their events again.
+			we.Status.Callbacks = nil
 		}
 		return nil
 	}
@@ -48,7 +59,11 @@ func (h *Handler) Run(req router.Request, _ router.Response) error {
 		return nil
 	}
 
-	if err := h.loadManifest(req, we); err != nil {
+	if ok, err := h.loadManifest(req, we, &wf); err != nil || !ok {
+		return err
+	}
+
+	if ok, err := h.admit(req, resp, we); err != nil || !ok {
 		return err
 	}
 
@@ -59,11 +74,21 @@ func (h *Handler) Run(req router.Request, _ router.Response) error {
 		}
 
 		we.Status.ThreadName = t.Name
+		if we.Status.CallbackToken == "" {
+			// Generated up front so that steps can pass the callback URLs of waitForEvent steps on before they run.
+			if we.Status.CallbackToken, err = randomtoken.Generate(); err != nil {
+				return err
+			}
+		}
 		if err = req.Client.Status().Update(req.Ctx, we); err != nil {
 			return err
 		}
 	}
 
+	if exceeded, err := h.checkBudget(req, resp, we); err != nil || exceeded {
+		return err
+	}
+
 	var (
 		steps        []kclient.Object
 		lastStepName = we.Spec.AfterWorkflowStepName
@@ -77,8 +102,9 @@ func (h *Handler) Run(req router.Request, _ router.Response) error {
 
 	if we.Status.WorkflowManifest.Output != "" {
 		newStep := workflowstep.NewStep(we.Namespace, we.Name, lastStepName, we.Spec.WorkflowGeneration, types.Step{
-			ID:   "output",
-			Step: we.Status.WorkflowManifest.Output,
+			ID:           "output",
+			Step:         we.Status.WorkflowManifest.Output,
+			OutputSchema: we.Status.WorkflowManifest.OutputSchema,
 		})
 		steps = append(steps, newStep)
 	}
@@ -88,6 +114,10 @@ func (h *Handler) Run(req router.Request, _ router.Response) error {
 		return err
 	}
 
+	if newState == types.WorkflowStateError && we.Status.WorkflowManifest.OnError != nil {
+		return h.runOnError(req, we, steps, output)
+	}
+
 	if newState.IsBlocked() {
 		we.Status.State = newState
 		we.Status.Error = output
@@ -96,6 +126,13 @@ func (h *Handler) Run(req router.Request, _ router.Response) error {
 
 	if newState == types.WorkflowStateComplete {
 		we.Status.Output = output
+		if len(steps) > 0 {
+			var lastStep v1.WorkflowStep
+			if err := req.Get(&lastStep, we.Namespace, steps[len(steps)-1].GetName()); err != nil {
+				return err
+			}
+			we.Status.StructuredOutput = lastStep.Status.StructuredOutput
+		}
 	} else if newState == types.WorkflowStateError {
 		we.Status.Error = output
 	}
@@ -109,16 +146,169 @@ func (h *Handler) Run(req router.Request, _ router.Response) error {
 	return apply.New(req.Client).Apply(req.Ctx, req.Object, steps...)
 }
 
-func (h *Handler) loadManifest(req router.Request, we *v1.WorkflowExecution) error {
-	var wf v1.Workflow
-	if err := req.Get(&wf, we.Namespace, we.Spec.WorkflowName); err != nil {
+// runOnError runs the onError steps of the workflow after a step failed. The steps start after the last step that
+// completed so that they see the conversation up to the failure. Once they finish, the execution is either recovered or
+// failed with the original error.
+func (h *Handler) runOnError(req router.Request, we *v1.WorkflowExecution, steps []kclient.Object, errMsg string) error {
+	if we.Status.OnError == nil {
+		we.Status.OnError = &v1.WorkflowOnErrorStatus{
+			Error: errMsg,
+		}
+	}
+
+	afterStepName := we.Spec.AfterWorkflowStepName
+	for _, obj := range steps {
+		var step v1.WorkflowStep
+		if err := req.Get(&step, we.Namespace, obj.GetName()); kclient.IgnoreNotFound(err) != nil {
+			return err
+		} else if err != nil || step.Status.State != types.WorkflowStateComplete {
+			break
+		}
+		afterStepName = step.Name
+	}
+
+	onErrorSteps := workflowstep.NewWorkflowOnErrorSteps(we.Namespace, we.Name, afterStepName, we.Spec.WorkflowGeneration,
+		*we.Status.WorkflowManifest.OnError, we.Status.OnError.Error)
+
+	var (
+		output string
+		state  = types.WorkflowStateComplete
+		err    error
+	)
+	if len(onErrorSteps) > 0 {
+		_, output, state, err = workflowstep.GetStateFromSteps(req.Ctx, req.Client, we.Spec.WorkflowGeneration, onErrorSteps...)
+		if err != nil {
+			return err
+		}
+	}
+
+	handled, recovered, failure := workflowstep.OnErrorResult(we.Status.WorkflowManifest.OnError, we.Status.OnError.Error, output, state)
+	if !handled {
+		we.Status.State = types.WorkflowStateRunning
+		return apply.New(req.Client).Apply(req.Ctx, req.Object, append(steps, onErrorSteps...)...)
+	}
+
+	we.Status.OnError.Handled = true
+	we.Status.OnError.Recovered = recovered
+	we.Status.Error = failure
+	if recovered {
+		we.Status.State = types.WorkflowStateRecovered
+		we.Status.Output = output
+	} else {
+		we.Status.State = types.WorkflowStateError
+	}
+	we.Status.WorkflowGeneration = we.Spec.WorkflowGeneration
+	if we.Status.EndTime == nil {
+		we.Status.EndTime = &metav1.Time{Time: time.Now()}
+	}
+
+	return apply.New(req.Client).Apply(req.Ctx, req.Object, append(steps, onErrorSteps...)...)
+}
+
+// cancel stops the execution and marks it as cancelled. The steps themselves are marked as cancelled by the workflow
+// step handler.
+func (h *Handler) cancel(req router.Request, we *v1.WorkflowExecution) error {
+	if err := h.stop(req, we, we.Spec.CancelledBy); err != nil {
 		return err
 	}
 
-	we.Status.WorkflowManifest = &wf.Spec.Manifest
+	if we.Status.State == types.WorkflowStateCancelled {
+		return nil
+	}
+
+	we.Status.State = types.WorkflowStateCancelled
+	we.Status.Error = "cancelled"
+	if we.Spec.CancelledBy == cancelledByBudget {
+		if exceeded := budgetExceeded(we); exceeded != "" {
+			we.Status.Error = exceeded
+		}
+	} else if we.Spec.CancelledBy != "" {
+		we.Status.Error += " by " + we.Spec.CancelledBy
+	}
+	we.Status.WorkflowGeneration = we.Spec.WorkflowGeneration
+	if we.Status.EndTime == nil {
+		we.Status.EndTime = &metav1.Time{Time: time.Now()}
+	}
 	return nil
 }
 
+// stop aborts the thread of the execution, which stops its in-flight runs, and cancels the subflows started by its
+// steps on behalf of cancelledBy.
+func (h *Handler) stop(req router.Request, we *v1.WorkflowExecution, cancelledBy string) error {
+	if we.Status.ThreadName == "" {
+		return nil
+	}
+
+	var thread v1.Thread
+	if err := req.Get(&thread, we.Namespace, we.Status.ThreadName); kclient.IgnoreNotFound(err) != nil {
+		return err
+	} else if err == nil && !thread.Spec.Abort {
+		thread.Spec.Abort = true
+		if err := req.Client.Update(req.Ctx, &thread); err != nil {
+			return err
+		}
+	}
+
+	subflows, err := listSubflows(req, we)
+	if err != nil {
+		return err
+	}
+
+	for _, subflow := range subflows {
+		if subflow.Spec.Cancel || subflow.Status.State.IsTerminal() {
+			continue
+		}
+		subflow.Spec.Cancel = true
+		subflow.Spec.CancelledBy = cancelledBy
+		if err := req.Client.Update(req.Ctx, &subflow); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func listSubflows(req router.Request, we *v1.WorkflowExecution) ([]v1.WorkflowExecution, error) {
+	if we.Status.ThreadName == "" {
+		return nil, nil
+	}
+
+	var subflows v1.WorkflowExecutionList
+	if err := req.List(&subflows, &kclient.ListOptions{
+		Namespace:     we.Namespace,
+		FieldSelector: fields.SelectorFromSet(map[string]string{"spec.parentThreadName": we.Status.ThreadName}),
+	}); err != nil {
+		return nil, err
+	}
+	return subflows.Items, nil
+}
+
+// loadManifest pins the execution to the latest revision of the workflow. The revision is kept until the execution
+// is rerun, so that editing the workflow doesn't change the steps of a running execution.
+func (h *Handler) loadManifest(req router.Request, we *v1.WorkflowExecution, wf *v1.Workflow) (bool, error) {
+	if we.Status.WorkflowManifest != nil && we.Status.WorkflowRevision > 0 && we.Status.WorkflowGeneration == we.Spec.WorkflowGeneration {
+		return true, nil
+	}
+
+	if wf.Status.Revision == 0 || wf.Status.RevisionGeneration != wf.Generation {
+		// Wait for the revision of the current manifest
+		return false, nil
+	}
+
+	var revision v1.WorkflowRevision
+	if err := req.Get(&revision, we.Namespace, workflow.RevisionName(wf.Name, wf.Status.Revision)); err != nil {
+		return false, err
+	}
+
+	if we.Status.WorkflowGeneration != we.Spec.WorkflowGeneration {
+		// An execution that is rerun while it is still running waits for its events again, too.
+		we.Status.Callbacks = nil
+	}
+	we.Status.WorkflowManifest = &revision.Spec.Manifest
+	we.Status.WorkflowRevision = revision.Spec.Revision
+	we.Status.WorkflowGeneration = we.Spec.WorkflowGeneration
+	return true, nil
+}
+
 func (h *Handler) newThread(ctx context.Context, c kclient.Client, wf *v1.Workflow, we *v1.WorkflowExecution) (*v1.Thread, error) {
 	workspaceName := we.Spec.WorkspaceName
 	if workspaceName == "" && wf.Spec.ThreadName != "" {
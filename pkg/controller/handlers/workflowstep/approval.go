@@ -0,0 +1,69 @@
+package workflowstep
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/obot-platform/nah/pkg/router"
+	"github.com/obot-platform/obot/apiclient/types"
+	ktime "github.com/obot-platform/obot/pkg/gateway/time"
+	v1 "github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RunApproval pauses the workflow until the approval is decided through the API. An approved step passes through
+// the run of the previous step, a rejected or timed out step fails the workflow.
+func (h *Handler) RunApproval(req router.Request, resp router.Response) error {
+	step := req.Object.(*v1.WorkflowStep)
+
+	if step.Spec.Step.Approval == nil {
+		return nil
+	}
+
+	if step.Status.Approval == nil {
+		step.Status.Approval = &v1.WorkflowStepApproval{
+			RequestedAt: metav1.Now(),
+		}
+	}
+
+	switch step.Status.Approval.Decision {
+	case v1.ApprovalDecisionApproved:
+		return passThrough(req, step)
+	case v1.ApprovalDecisionRejected:
+		step.Status.State = types.WorkflowStateError
+		step.Status.Error = rejectionMessage(step.Status.Approval)
+		return nil
+	}
+
+	if step.Spec.Step.Approval.Timeout != "" {
+		timeout, err := ktime.ParseDuration(step.Spec.Step.Approval.Timeout)
+		if err != nil {
+			step.Status.State = types.WorkflowStateError
+			step.Status.Error = fmt.Sprintf("invalid approval timeout %q: %v", step.Spec.Step.Approval.Timeout, err)
+			return nil
+		}
+
+		remaining := time.Until(step.Status.Approval.RequestedAt.Add(timeout))
+		if remaining <= 0 {
+			step.Status.State = types.WorkflowStateError
+			step.Status.Error = fmt.Sprintf("approval was not given within %s", timeout)
+			return nil
+		}
+		resp.RetryAfter(remaining)
+	}
+
+	step.Status.State = types.WorkflowStateWaitingApproval
+	step.Status.Error = ""
+	return nil
+}
+
+func rejectionMessage(approval *v1.WorkflowStepApproval) string {
+	msg := "rejected"
+	if approval.DecidedBy != "" {
+		msg += " by " + approval.DecidedBy
+	}
+	if approval.Comment != "" {
+		msg += ": " + approval.Comment
+	}
+	return msg
+}
@@ -0,0 +1,247 @@
+package workflowstep
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/obot-platform/nah/pkg/apply"
+	"github.com/obot-platform/nah/pkg/router"
+	"github.com/obot-platform/obot/apiclient/types"
+	"github.com/obot-platform/obot/pkg/invoke"
+	v1 "github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1"
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func (h *Handler) RunForEach(req router.Request, _ router.Response) (err error) {
+	step := req.Object.(*v1.WorkflowStep)
+
+	if step.Spec.Step.ForEach == nil {
+		return nil
+	}
+
+	var completeResponse bool
+	var objects []kclient.Object
+	defer func() {
+		apply := apply.New(req.Client)
+		if !completeResponse {
+			apply.WithNoPrune()
+		}
+		if applyErr := apply.Apply(req.Ctx, req.Object, objects...); applyErr != nil && err == nil {
+			err = applyErr
+		}
+	}()
+
+	// reset
+	step.Status.Error = ""
+
+	items, errMsg, err := h.forEachItems(req, step)
+	if err != nil {
+		return err
+	} else if errMsg != "" {
+		step.Status.State = types.WorkflowStateError
+		step.Status.Error = errMsg
+		return nil
+	}
+
+	if len(items) == 0 || len(step.Spec.Step.ForEach.Steps) == 0 {
+		// Nothing to iterate over, so pass through the run of the previous step.
+		completeResponse = true
+		return passThrough(req, step)
+	}
+
+	var (
+		parallelism   = max(step.Spec.Step.ForEach.Parallelism, 1)
+		sequential    = parallelism == 1
+		afterStepName = step.Spec.AfterWorkflowStepName
+		outputs       = make([]string, 0, len(items))
+		joinPrev      *v1.WorkflowStep
+		lastRunName   string
+		running       int
+	)
+
+	for i, item := range items {
+		if running >= parallelism {
+			break
+		}
+
+		steps := h.defineForEachIteration(step, afterStepName, i, item)
+		objects = append(objects, steps...)
+
+		runName, output, state, err := GetStateFromSteps(req.Ctx, req.Client, step.Spec.WorkflowGeneration, steps...)
+		if err != nil {
+			return err
+		}
+
+		if state.IsBlocked() {
+			step.Status.State = state
+			step.Status.Error = output
+			return nil
+		}
+
+		if state != types.WorkflowStateComplete {
+			running++
+			continue
+		}
+
+		lastStep := steps[len(steps)-1].(*v1.WorkflowStep)
+		if sequential {
+			// Each iteration continues from the chat history of the previous one.
+			afterStepName = lastStep.Name
+		}
+		if joinPrev == nil {
+			joinPrev = lastStep
+		}
+		outputs = append(outputs, output)
+		lastRunName = runName
+	}
+
+	if len(outputs) != len(items) {
+		step.Status.State = types.WorkflowStateRunning
+		return nil
+	}
+
+	if sequential {
+		completeResponse = true
+		step.Status.State = types.WorkflowStateComplete
+		step.Status.LastRunName = lastRunName
+		return nil
+	}
+
+	joinStep := h.defineJoin(step, joinPrev, "The following steps were run once for each item of a list.", "ITEM", outputs)
+	objects = append(objects, joinStep)
+	completeResponse = true
+
+	runName, errMsg, newState, err := GetStateFromSteps(req.Ctx, req.Client, step.Spec.WorkflowGeneration, joinStep)
+	if err != nil {
+		return err
+	}
+
+	if newState.IsBlocked() {
+		step.Status.State = newState
+		step.Status.Error = errMsg
+		return nil
+	}
+
+	step.Status.State = newState
+	step.Status.LastRunName = runName
+	return nil
+}
+
+// defineForEachIteration returns the steps run for the item at the given index. The step IDs get an index suffix so
+// that each iteration has its own set of steps.
+func (h *Handler) defineForEachIteration(step *v1.WorkflowStep, afterStepName string, index int, item string) (result []kclient.Object) {
+	for _, itemStep := range step.Spec.Step.ForEach.Steps {
+		itemStep.ID = fmt.Sprintf("%s{index=%d}", itemStep.ID, index)
+		newStep := newChildStep(step, afterStepName, itemStep)
+		newStep.Spec.Item = item
+		result = append(result, newStep)
+		afterStepName = newStep.Name
+	}
+
+	return result
+}
+
+// forEachItems resolves the items of a forEach step. The items can be a literal JSON array, the workflow input or a
+// field of it ("input", "input.field"), the output of a prior step or a field of it ("steps.<id>", "steps.<id>.output",
+// "steps.<id>.output.field"), or, if not set, the output of the previous step. Like for params, the input of an
+// execution started by a webhook or an email is its payload or body. If the items can't be resolved, a message
+// describing the problem is returned.
+func (h *Handler) forEachItems(req router.Request, step *v1.WorkflowStep) (_ []string, errMsg string, _ error) {
+	var (
+		source = strings.TrimSpace(step.Spec.Step.ForEach.Items)
+		data   string
+	)
+
+	switch {
+	case strings.HasPrefix(source, "["):
+		data = source
+	case source == "input" || strings.HasPrefix(source, "input."):
+		var wfe v1.WorkflowExecution
+		if err := req.Get(&wfe, step.Namespace, step.Spec.WorkflowExecutionName); err != nil {
+			return nil, "", err
+		}
+		value, err := invoke.JSONField(invoke.ParamsInput(&wfe), strings.TrimPrefix(strings.TrimPrefix(source, "input"), "."))
+		if err != nil {
+			return nil, fmt.Sprintf("failed to read forEach items from %s: %v", source, err), nil
+		}
+		data = value
+	case strings.HasPrefix(source, "steps."):
+		stepID, path, _ := strings.Cut(strings.TrimPrefix(source, "steps."), ".")
+		field, ok := strings.CutPrefix(path, "output")
+		if path == "" {
+			ok = true
+		}
+		if stepID == "" || !ok || (field != "" && !strings.HasPrefix(field, ".")) {
+			return nil, fmt.Sprintf("invalid forEach items %q, expected steps.<id>.output or steps.<id>.output.<field>", source), nil
+		}
+		output, found, err := invoke.StepOutput(req.Ctx, req.Client, step, stepID)
+		if err != nil {
+			return nil, "", err
+		} else if !found {
+			return nil, fmt.Sprintf("failed to read forEach items: step %s has not completed", stepID), nil
+		}
+		value, err := invoke.JSONField(output, strings.TrimPrefix(field, "."))
+		if err != nil {
+			return nil, fmt.Sprintf("failed to read forEach items from %s: %v", source, err), nil
+		}
+		data = value
+	case source == "":
+		if step.Spec.AfterWorkflowStepName == "" {
+			var wfe v1.WorkflowExecution
+			if err := req.Get(&wfe, step.Namespace, step.Spec.WorkflowExecutionName); err != nil {
+				return nil, "", err
+			}
+			data = invoke.ParamsInput(&wfe)
+			break
+		}
+		var previousStep v1.WorkflowStep
+		if err := req.Get(&previousStep, step.Namespace, step.Spec.AfterWorkflowStepName); err != nil {
+			return nil, "", err
+		}
+		output, err := invoke.RunOutput(req.Ctx, req.Client, step.Namespace, previousStep.Status.LastRunName)
+		if err != nil {
+			return nil, "", err
+		}
+		data = output
+	default:
+		return nil, fmt.Sprintf("invalid forEach items %q, expected a JSON array, input, input.<field>, or steps.<id>.output", source), nil
+	}
+
+	items, err := parseItems(data)
+	if err != nil {
+		return nil, fmt.Sprintf("forEach items are not a JSON array: %v", err), nil
+	}
+	return items, "", nil
+}
+
+// parseItems parses a JSON array, allowing for the array to be wrapped in text or a markdown code block as LLMs like
+// to do. String items are returned as is and all other items as compact JSON.
+func parseItems(data string) ([]string, error) {
+	data = strings.TrimSpace(data)
+	if start, end := strings.Index(data, "["), strings.LastIndex(data, "]"); start >= 0 && end > start {
+		data = data[start : end+1]
+	}
+
+	var raw []json.RawMessage
+	if err := json.Unmarshal([]byte(data), &raw); err != nil {
+		return nil, err
+	}
+
+	items := make([]string, 0, len(raw))
+	for _, item := range raw {
+		var s string
+		if err := json.Unmarshal(item, &s); err == nil {
+			items = append(items, s)
+			continue
+		}
+		buf := &bytes.Buffer{}
+		if err := json.Compact(buf, item); err != nil {
+			return nil, err
+		}
+		items = append(items, buf.String())
+	}
+
+	return items, nil
+}
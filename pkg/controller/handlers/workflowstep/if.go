@@ -153,7 +153,7 @@ func (h *Handler) defineCondition(step, afterStep *v1.WorkflowStep, iteration in
 		suffix = fmt.Sprintf("{condition,index=%d}", iteration)
 	}
 
-	newStep := NewStep(step.Namespace, step.Spec.WorkflowExecutionName, afterStepName, step.Spec.WorkflowGeneration, types.Step{
+	newStep := newChildStep(step, afterStepName, types.Step{
 		ID:   step.Spec.Step.ID + suffix,
 		Step: toStepCondition(condition),
 	})
@@ -174,7 +174,7 @@ func (h *Handler) defineIfSteps(step *v1.WorkflowStep, conditionStep *v1.Workflo
 		if i > 0 {
 			afterStepName = lastStepName
 		}
-		newStep := NewStep(step.Namespace, step.Spec.WorkflowExecutionName, afterStepName, step.Spec.WorkflowGeneration, ifStep)
+		newStep := newChildStep(step, afterStepName, ifStep)
 		result = append(result, newStep)
 		lastStepName = newStep.Name
 	}
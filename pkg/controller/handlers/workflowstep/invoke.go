@@ -1,6 +1,8 @@
 package workflowstep
 
 import (
+	"errors"
+
 	"github.com/gptscript-ai/go-gptscript"
 	"github.com/obot-platform/nah/pkg/router"
 	"github.com/obot-platform/nah/pkg/uncached"
@@ -12,7 +14,7 @@ import (
 	kclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-func (h *Handler) RunInvoke(req router.Request, _ router.Response) error {
+func (h *Handler) RunInvoke(req router.Request, resp router.Response) error {
 	var (
 		ctx         = req.Ctx
 		client      = req.Client
@@ -20,7 +22,8 @@ func (h *Handler) RunInvoke(req router.Request, _ router.Response) error {
 		lastRunName string
 	)
 
-	if step.Spec.Step.If != nil || step.Spec.Step.While != nil {
+	if step.Spec.Step.If != nil || step.Spec.Step.While != nil || step.Spec.Step.Parallel != nil || step.Spec.Step.ForEach != nil ||
+		step.Spec.Step.Approval != nil || step.Spec.Step.Switch != nil || step.Spec.Step.Wait != nil {
 		return nil
 	}
 
@@ -34,10 +37,26 @@ func (h *Handler) RunInvoke(req router.Request, _ router.Response) error {
 
 	var run v1.Run
 	if len(step.Status.RunNames) == 0 {
+		if step.Spec.Step.WaitForEvent != nil {
+			if waiting, err := h.waitForEvent(req, resp, step); err != nil || waiting {
+				return err
+			}
+		}
+
+		if wait := retryWait(step); wait > 0 {
+			resp.RetryAfter(wait)
+			return nil
+		}
+
 		invokeResp, err := h.invoker.Step(ctx, req.Client, step, invoke.StepOptions{
 			PreviousRunName: lastRunName,
 		})
-		if err != nil {
+		if errors.As(err, &invoke.ErrStepConfig{}) {
+			// Invoking the step again would fail the same way
+			step.Status.State = types.WorkflowStateError
+			step.Status.Error = err.Error()
+			return nil
+		} else if err != nil {
 			return err
 		}
 		defer invokeResp.Close()
@@ -56,12 +75,26 @@ func (h *Handler) RunInvoke(req router.Request, _ router.Response) error {
 
 		run = *invokeResp.Run
 	} else {
-		if err := req.Get(&run, step.Namespace, step.Status.RunNames[0]); err != nil {
+		if err := req.Get(&run, step.Namespace, currentRunName(step)); err != nil {
 			return err
 		}
 	}
 
-	return h.setStepStateFromRun(req, step, &run)
+	if err := h.setStepStateFromRun(req, step, &run); err != nil {
+		return err
+	}
+
+	if err := h.checkOutputSchema(req, step); err != nil {
+		return err
+	}
+
+	if step.Status.State.IsTerminal() {
+		recordAttempt(step, step.Status.LastRunName, step.Status.Error)
+		// If the step is retried, the status update will requeue it and the backoff is applied above.
+		retryFailedStep(step)
+	}
+
+	return nil
 }
 
 func (h *Handler) getResultFromNext(req router.Request, step *v1.WorkflowStep, run *v1.Run) error {
@@ -80,9 +113,17 @@ func (h *Handler) getResultFromNext(req router.Request, step *v1.WorkflowStep, r
 func (h *Handler) setStepStateFromRun(req router.Request, step *v1.WorkflowStep, run *v1.Run) error {
 	switch run.Status.State {
 	case gptscript.Finished:
-		step.Status.State = types.WorkflowStateBlocked
-		step.Status.LastRunName = step.Status.RunNames[0]
-		step.Status.Error = "Aborted"
+		if run.Spec.ToolCall {
+			// Tools called directly finish instead of waiting for the next chat message.
+			step.Status.State = types.WorkflowStateComplete
+			step.Status.LastRunName = currentRunName(step)
+			step.Status.SubCalls = nil
+			step.Status.Error = ""
+		} else {
+			step.Status.State = types.WorkflowStateBlocked
+			step.Status.LastRunName = currentRunName(step)
+			step.Status.Error = "Aborted"
+		}
 	case gptscript.Continue:
 		if run.Status.SubCall != nil {
 			step.Status.State = types.WorkflowStateSubCall
@@ -91,13 +132,13 @@ func (h *Handler) setStepStateFromRun(req router.Request, step *v1.WorkflowStep,
 			return h.getResultFromNext(req, step, run)
 		} else {
 			step.Status.State = types.WorkflowStateComplete
-			step.Status.LastRunName = step.Status.RunNames[0]
+			step.Status.LastRunName = currentRunName(step)
 			step.Status.SubCalls = nil
 		}
 		step.Status.Error = ""
 	case gptscript.Error:
 		step.Status.State = types.WorkflowStateError
-		step.Status.LastRunName = step.Status.RunNames[0]
+		step.Status.LastRunName = currentRunName(step)
 		step.Status.Error = run.Status.Error
 	}
 	return nil
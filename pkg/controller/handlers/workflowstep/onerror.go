@@ -0,0 +1,114 @@
+package workflowstep
+
+import (
+	"fmt"
+
+	"github.com/obot-platform/nah/pkg/apply"
+	"github.com/obot-platform/nah/pkg/router"
+	"github.com/obot-platform/obot/apiclient/types"
+	v1 "github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1"
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// RunOnError runs the onError steps of a step that failed. The failed step stays running while they run and then
+// either completes, if the onError block marks the failure as recovered, or fails with the original error.
+func (h *Handler) RunOnError(req router.Request, _ router.Response) error {
+	step := req.Object.(*v1.WorkflowStep)
+
+	if step.Spec.Step.OnError == nil || !step.IsGenerationInSync() || step.Status.State == types.WorkflowStateCancelled {
+		return nil
+	}
+
+	if step.Status.OnError == nil {
+		if step.Status.State != types.WorkflowStateError {
+			return nil
+		}
+		step.Status.OnError = &v1.WorkflowOnErrorStatus{
+			Error: step.Status.Error,
+		}
+	}
+
+	if step.Status.OnError.Handled {
+		return nil
+	}
+
+	steps := defineOnErrorSteps(step, *step.Spec.Step.OnError, step.Status.OnError.Error)
+	if err := apply.New(req.Client).WithOwnerSubContext("onerror").Apply(req.Ctx, req.Object, steps...); err != nil {
+		return err
+	}
+
+	var (
+		runName string
+		errMsg  string
+		state   = types.WorkflowStateComplete
+		err     error
+	)
+	if len(steps) > 0 {
+		runName, errMsg, state, err = GetStateFromSteps(req.Ctx, req.Client, step.Spec.WorkflowGeneration, steps...)
+		if err != nil {
+			return err
+		}
+	} else if runName, err = previousLastRunName(req, step); err != nil {
+		return err
+	}
+
+	handled, recovered, failure := OnErrorResult(step.Spec.Step.OnError, step.Status.OnError.Error, errMsg, state)
+	if !handled {
+		step.Status.State = types.WorkflowStateRunning
+		return nil
+	}
+
+	step.Status.OnError.Handled = true
+	step.Status.OnError.Recovered = recovered
+	if recovered {
+		step.Status.State = types.WorkflowStateComplete
+		step.Status.LastRunName = runName
+		step.Status.Error = ""
+	} else {
+		step.Status.State = types.WorkflowStateError
+		step.Status.Error = failure
+	}
+	return nil
+}
+
+// OnErrorResult decides the outcome of an onError block from the state of its steps. It returns whether the block
+// finished, whether the failure is recovered and otherwise the error to fail with.
+func OnErrorResult(onError *types.OnError, originalErr, errMsg string, state types.WorkflowState) (handled, recovered bool, failure string) {
+	switch {
+	case state.IsBlocked():
+		return true, false, fmt.Sprintf("%s; onError steps failed: %s", originalErr, errMsg)
+	case state == types.WorkflowStateComplete:
+		return true, onError.Recover, originalErr
+	default:
+		return false, false, ""
+	}
+}
+
+// defineOnErrorSteps defines the onError steps of a failed step. Their IDs are suffixed with "{onError=<id>}", where
+// the ID is the one of the failed step including its own suffix, so that a step that fails in several iterations of a
+// loop gets separate onError steps for each of them.
+func defineOnErrorSteps(step *v1.WorkflowStep, onError types.OnError, errMsg string) (result []kclient.Object) {
+	lastStepName := step.Spec.AfterWorkflowStepName
+	for _, onErrorStep := range onError.Steps {
+		onErrorStep.ID = fmt.Sprintf("%s{onError=%s}", onErrorStep.ID, step.Spec.Step.ID)
+		newStep := newChildStep(step, lastStepName, onErrorStep)
+		newStep.Spec.Error = errMsg
+		result = append(result, newStep)
+		lastStepName = newStep.Name
+	}
+	return result
+}
+
+// NewWorkflowOnErrorSteps defines the steps of the onError block of a workflow. Like the onError steps of a step, they
+// start after the given step and get the error as their input, their IDs are suffixed with "{onError}".
+func NewWorkflowOnErrorSteps(namespace, workflowExecutionName, afterStepName string, generation int64, onError types.OnError, errMsg string) (result []kclient.Object) {
+	lastStepName := afterStepName
+	for _, onErrorStep := range onError.Steps {
+		onErrorStep.ID += "{onError}"
+		newStep := NewStep(namespace, workflowExecutionName, lastStepName, generation, onErrorStep)
+		newStep.Spec.Error = errMsg
+		result = append(result, newStep)
+		lastStepName = newStep.Name
+	}
+	return result
+}
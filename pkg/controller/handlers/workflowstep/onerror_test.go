@@ -0,0 +1,71 @@
+package workflowstep
+
+import (
+	"testing"
+
+	"github.com/obot-platform/obot/apiclient/types"
+	v1 "github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1"
+)
+
+func TestDefineOnErrorStepsInLoop(t *testing.T) {
+	onError := types.OnError{
+		Steps: []types.Step{
+			{ID: "notify", Step: "Report the error"},
+			{ID: "cleanup", Step: "Clean up"},
+		},
+	}
+
+	names := map[string]string{}
+	for _, iteration := range []struct {
+		stepID string
+		item   string
+		err    string
+	}{
+		{stepID: "fetch{index=0}", item: `"a"`, err: "first failure"},
+		{stepID: "fetch{index=1}", item: `"b"`, err: "second failure"},
+	} {
+		failed := &v1.WorkflowStep{
+			Spec: v1.WorkflowStepSpec{
+				AfterWorkflowStepName: "ws1abc-loop",
+				Step:                  types.Step{ID: iteration.stepID},
+				WorkflowExecutionName: "we1abc",
+				WorkflowGeneration:    2,
+				Item:                  iteration.item,
+				Error:                 "error of the parent",
+			},
+		}
+		failed.Name = "ws1abc-" + iteration.stepID
+		failed.Namespace = "default"
+
+		steps := defineOnErrorSteps(failed, onError, iteration.err)
+		if len(steps) != len(onError.Steps) {
+			t.Fatalf("%s: got %d steps, want %d", iteration.stepID, len(steps), len(onError.Steps))
+		}
+
+		afterStepName := failed.Spec.AfterWorkflowStepName
+		for i, obj := range steps {
+			step := obj.(*v1.WorkflowStep)
+			wantID := onError.Steps[i].ID + "{onError=" + iteration.stepID + "}"
+			if step.Spec.Step.ID != wantID {
+				t.Errorf("got ID %q, want %q", step.Spec.Step.ID, wantID)
+			}
+			if other, ok := names[step.Name]; ok {
+				t.Errorf("steps %q and %q have the same name %q", other, step.Spec.Step.ID, step.Name)
+			}
+			names[step.Name] = step.Spec.Step.ID
+			if step.Spec.AfterWorkflowStepName != afterStepName {
+				t.Errorf("%s: got after step %q, want %q", step.Spec.Step.ID, step.Spec.AfterWorkflowStepName, afterStepName)
+			}
+			if step.Spec.Item != iteration.item {
+				t.Errorf("%s: got item %q, want %q", step.Spec.Step.ID, step.Spec.Item, iteration.item)
+			}
+			if step.Spec.Error != iteration.err {
+				t.Errorf("%s: got error %q, want %q", step.Spec.Step.ID, step.Spec.Error, iteration.err)
+			}
+			if step.Spec.WorkflowGeneration != failed.Spec.WorkflowGeneration {
+				t.Errorf("%s: got generation %d, want %d", step.Spec.Step.ID, step.Spec.WorkflowGeneration, failed.Spec.WorkflowGeneration)
+			}
+			afterStepName = step.Name
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package workflowstep
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/obot-platform/nah/pkg/router"
+	"github.com/obot-platform/nah/pkg/uncached"
+	"github.com/obot-platform/obot/apiclient/types"
+	"github.com/obot-platform/obot/pkg/invoke"
+	v1 "github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1"
+	"github.com/xeipuuv/gojsonschema"
+	"k8s.io/client-go/util/retry"
+)
+
+const defaultOutputSchemaRetries = 2
+
+// checkOutputSchema validates the output of a completed step against the output schema of the step. If the output
+// doesn't match, the model is asked to correct it until the retries are used up, after which the step fails.
+func (h *Handler) checkOutputSchema(req router.Request, step *v1.WorkflowStep) error {
+	outputSchema := step.Spec.Step.OutputSchema
+	if outputSchema == nil || len(outputSchema.Schema) == 0 || step.Status.State != types.WorkflowStateComplete {
+		return nil
+	}
+
+	output, err := invoke.RunOutput(req.Ctx, req.Client, step.Namespace, step.Status.LastRunName)
+	if err != nil {
+		return err
+	}
+
+	structured, problems, err := validateOutput(outputSchema.Schema, output)
+	if err != nil {
+		step.Status.State = types.WorkflowStateError
+		step.Status.Error = fmt.Sprintf("invalid output schema: %v", err)
+		return nil
+	}
+
+	if len(problems) == 0 {
+		step.Status.StructuredOutput = structured
+		return nil
+	}
+
+	maxRetries := defaultOutputSchemaRetries
+	if outputSchema.MaxRetries != nil {
+		maxRetries = *outputSchema.MaxRetries
+	}
+	if step.Spec.Step.Tool != nil {
+		// There is no model to ask for a correction.
+		maxRetries = 0
+	}
+
+	if len(step.Status.OutputSchemaRunNames) >= maxRetries {
+		step.Status.State = types.WorkflowStateError
+		step.Status.Error = fmt.Sprintf("output does not match the output schema after %d retries: %s",
+			len(step.Status.OutputSchemaRunNames), strings.Join(problems, "; "))
+		return nil
+	}
+
+	correction := toOutputSchemaCorrection(outputSchema.Schema, problems)
+	invokeResp, err := h.invoker.Step(req.Ctx, req.Client, step, invoke.StepOptions{
+		PreviousRunName: step.Status.LastRunName,
+		Continue:        &correction,
+	})
+	if err != nil {
+		return err
+	}
+	defer invokeResp.Close()
+
+	// Record the run right away, like the first run of the step, so that it isn't started twice.
+	err = retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		if err := req.Client.Get(req.Ctx, router.Key(step.Namespace, step.Name), uncached.Get(step)); err != nil {
+			return err
+		}
+		step.Status.OutputSchemaRunNames = append(step.Status.OutputSchemaRunNames, invokeResp.Run.Name)
+		return req.Client.Status().Update(req.Ctx, step)
+	})
+	if err != nil {
+		return err
+	}
+
+	step.Status.State = types.WorkflowStateRunning
+	step.Status.LastRunName = ""
+	return nil
+}
+
+// currentRunName returns the run that decides the state of the step, which is the last correction if the output
+// didn't match the output schema.
+func currentRunName(step *v1.WorkflowStep) string {
+	if n := len(step.Status.OutputSchemaRunNames); n > 0 {
+		return step.Status.OutputSchemaRunNames[n-1]
+	}
+	return step.Status.RunNames[0]
+}
+
+// validateOutput parses the output as JSON and validates it against the schema. On success, the output is returned as
+// compact JSON. Otherwise, the reasons the output doesn't match are returned. An error is only returned if the schema
+// itself is invalid.
+func validateOutput(schema json.RawMessage, output string) (string, []string, error) {
+	compiled, err := gojsonschema.NewSchema(gojsonschema.NewBytesLoader(schema))
+	if err != nil {
+		return "", nil, err
+	}
+
+	data := extractJSON(output)
+	if !json.Valid([]byte(data)) {
+		return "", []string{"the output is not valid JSON"}, nil
+	}
+
+	result, err := compiled.Validate(gojsonschema.NewStringLoader(data))
+	if err != nil {
+		return "", []string{err.Error()}, nil
+	}
+
+	if !result.Valid() {
+		problems := make([]string, 0, len(result.Errors()))
+		for _, resultErr := range result.Errors() {
+			problems = append(problems, resultErr.String())
+		}
+		return "", problems, nil
+	}
+
+	buf := &bytes.Buffer{}
+	if err := json.Compact(buf, []byte(data)); err != nil {
+		return "", nil, err
+	}
+	return buf.String(), nil, nil
+}
+
+// extractJSON returns the JSON document in the output, allowing for the document to be wrapped in text or a markdown
+// code block.
+func extractJSON(output string) string {
+	output = strings.TrimSpace(output)
+	if json.Valid([]byte(output)) {
+		return output
+	}
+
+	start := strings.IndexAny(output, "{[")
+	if start < 0 {
+		return output
+	}
+
+	closing := "}"
+	if output[start] == '[' {
+		closing = "]"
+	}
+	if end := strings.LastIndex(output, closing); end > start {
+		return output[start : end+1]
+	}
+	return output
+}
+
+func toOutputSchemaCorrection(schema json.RawMessage, problems []string) string {
+	var sb strings.Builder
+	sb.WriteString("Your response does not match the required JSON schema:\n")
+	for _, problem := range problems {
+		sb.WriteString("- ")
+		sb.WriteString(problem)
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\nRespond again with only a JSON document that matches this JSON schema:\n")
+	sb.Write(schema)
+	return sb.String()
+}
@@ -0,0 +1,130 @@
+package workflowstep
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/obot-platform/nah/pkg/apply"
+	"github.com/obot-platform/nah/pkg/router"
+	"github.com/obot-platform/obot/apiclient/types"
+	v1 "github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1"
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func (h *Handler) RunParallel(req router.Request, _ router.Response) (err error) {
+	step := req.Object.(*v1.WorkflowStep)
+
+	if step.Spec.Step.Parallel == nil {
+		return nil
+	}
+
+	var objects []kclient.Object
+	defer func() {
+		if applyErr := apply.New(req.Client).Apply(req.Ctx, req.Object, objects...); applyErr != nil && err == nil {
+			err = applyErr
+		}
+	}()
+
+	var (
+		branches = h.defineParallelBranches(step)
+		outputs  = make([]string, 0, len(branches))
+		joinPrev *v1.WorkflowStep
+		done     = true
+	)
+
+	// reset
+	step.Status.Error = ""
+
+	for _, branch := range branches {
+		objects = append(objects, branch...)
+	}
+
+	for _, branch := range branches {
+		_, output, state, err := GetStateFromSteps(req.Ctx, req.Client, step.Spec.WorkflowGeneration, branch...)
+		if err != nil {
+			return err
+		}
+
+		if state.IsBlocked() {
+			step.Status.State = state
+			step.Status.Error = output
+			return nil
+		}
+
+		if state != types.WorkflowStateComplete {
+			done = false
+			continue
+		}
+
+		outputs = append(outputs, output)
+		if joinPrev == nil {
+			joinPrev = branch[len(branch)-1].(*v1.WorkflowStep)
+		}
+	}
+
+	if !done {
+		step.Status.State = types.WorkflowStateRunning
+		return nil
+	}
+
+	if joinPrev == nil {
+		// No branches have any steps, so pass through the run of the previous step.
+		return passThrough(req, step)
+	}
+
+	joinStep := h.defineJoin(step, joinPrev, "The following steps were run in parallel.", "BRANCH", outputs)
+	objects = append(objects, joinStep)
+
+	runName, errMsg, newState, err := GetStateFromSteps(req.Ctx, req.Client, step.Spec.WorkflowGeneration, joinStep)
+	if err != nil {
+		return err
+	}
+
+	if newState.IsBlocked() {
+		step.Status.State = newState
+		step.Status.Error = errMsg
+		return nil
+	}
+
+	step.Status.State = newState
+	step.Status.LastRunName = runName
+	return nil
+}
+
+// defineParallelBranches returns the steps of each non-empty branch. Every branch starts after the same step as the
+// parallel step itself, so all branches are started at the same time and each continues from the same chat history.
+func (h *Handler) defineParallelBranches(step *v1.WorkflowStep) (result [][]kclient.Object) {
+	for _, branch := range step.Spec.Step.Parallel.Branches {
+		var (
+			lastStepName = step.Spec.AfterWorkflowStepName
+			steps        []kclient.Object
+		)
+		for _, branchStep := range branch.Steps {
+			newStep := newChildStep(step, lastStepName, branchStep)
+			steps = append(steps, newStep)
+			lastStepName = newStep.Name
+		}
+		if len(steps) > 0 {
+			result = append(result, steps)
+		}
+	}
+
+	return result
+}
+
+func (h *Handler) defineJoin(step, afterStep *v1.WorkflowStep, description, label string, outputs []string) *v1.WorkflowStep {
+	return newChildStep(step, afterStep.Name, types.Step{
+		ID:   step.Spec.Step.ID + "{join}",
+		Step: toJoinStep(description, label, outputs),
+	})
+}
+
+func toJoinStep(description, label string, outputs []string) string {
+	var sb strings.Builder
+	sb.WriteString(description)
+	sb.WriteString(" Respond with their combined results, preserving all details, for use in later steps.\n")
+	for i, output := range outputs {
+		sb.WriteString(fmt.Sprintf("\nRESULT OF %s %d:\n%s\n", label, i+1, output))
+	}
+	return sb.String()
+}
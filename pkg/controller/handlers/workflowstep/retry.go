@@ -0,0 +1,152 @@
+package workflowstep
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/obot-platform/obot/apiclient/types"
+	v1 "github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	defaultRetryBackoff    = 10 * time.Second
+	defaultRetryMaxBackoff = 10 * time.Minute
+
+	retryOnAll     = "all"
+	retryOnTimeout = "timeout"
+	retryOnModel   = "model"
+	retryOnTool    = "tool"
+
+	// errorKindUnknown is the kind of errors that can't be classified, which are only retried on all errors.
+	errorKindUnknown = "unknown"
+)
+
+// ValidateRetry checks the retry policy of a step.
+func ValidateRetry(retry *types.RetryPolicy) error {
+	if retry == nil {
+		return nil
+	}
+	if retry.MaxAttempts < 1 {
+		return fmt.Errorf("maxAttempts must be at least 1")
+	}
+	for _, d := range []string{retry.Backoff, retry.MaxBackoff} {
+		if d == "" {
+			continue
+		}
+		if parsed, err := time.ParseDuration(d); err != nil || parsed <= 0 {
+			return fmt.Errorf("invalid duration %q, must be a positive duration like 30s", d)
+		}
+	}
+	for _, on := range retry.On {
+		switch on {
+		case retryOnAll, retryOnTimeout, retryOnModel, retryOnTool:
+		default:
+			return fmt.Errorf("invalid retry on %q, must be one of %s, %s, %s, %s", on, retryOnAll, retryOnTimeout, retryOnModel, retryOnTool)
+		}
+	}
+	return nil
+}
+
+// recordAttempt adds the run of the current attempt to the step's attempts, unless it is already recorded.
+func recordAttempt(step *v1.WorkflowStep, runName, errMsg string) {
+	if runName == "" {
+		return
+	}
+	if len(step.Status.Attempts) > 0 && step.Status.Attempts[len(step.Status.Attempts)-1].RunName == runName {
+		return
+	}
+	step.Status.Attempts = append(step.Status.Attempts, v1.WorkflowStepAttempt{
+		RunName: runName,
+		Error:   errMsg,
+		EndTime: metav1.Now(),
+	})
+}
+
+// retryFailedStep should be called once the step is finished. If the step failed and its retry policy allows
+// another attempt, then the runs are reset so that a new run is started after the backoff.
+func retryFailedStep(step *v1.WorkflowStep) {
+	retry := step.Spec.Step.Retry
+	if retry == nil || step.Status.State != types.WorkflowStateError {
+		return
+	}
+
+	if len(step.Status.Attempts) >= retry.MaxAttempts || !shouldRetryOn(retry.On, step.Status.Error) {
+		return
+	}
+
+	// The failed run is kept for inspection and will be cleaned up with the step.
+	step.Status.State = types.WorkflowStateRunning
+	step.Status.RunNames = nil
+	step.Status.OutputSchemaRunNames = nil
+	step.Status.LastRunName = ""
+	step.Status.SubCalls = nil
+}
+
+// retryWait returns how long to wait before starting the next attempt of the step.
+func retryWait(step *v1.WorkflowStep) time.Duration {
+	retry := step.Spec.Step.Retry
+	if retry == nil || len(step.Status.Attempts) == 0 {
+		return 0
+	}
+
+	backoff := parseDurationOrDefault(retry.Backoff, defaultRetryBackoff)
+	maxBackoff := parseDurationOrDefault(retry.MaxBackoff, defaultRetryMaxBackoff)
+	for range len(step.Status.Attempts) - 1 {
+		backoff *= 2
+		if backoff >= maxBackoff {
+			break
+		}
+	}
+	backoff = min(backoff, maxBackoff)
+
+	lastAttempt := step.Status.Attempts[len(step.Status.Attempts)-1]
+	return time.Until(lastAttempt.EndTime.Add(backoff))
+}
+
+func shouldRetryOn(on []string, errMsg string) bool {
+	if len(on) == 0 || slices.Contains(on, retryOnAll) {
+		return true
+	}
+	return slices.Contains(on, errorKind(errMsg))
+}
+
+// errorKind makes a best effort to classify the error of a failed run from the words of its message. Errors of a tool
+// call are attributed to the tool, even if the tool reported a timeout or rate limit of its own. Errors that don't
+// clearly come from the model or a tool call are of unknown kind.
+func errorKind(errMsg string) string {
+	words := " " + strings.Join(wordRegexp.FindAllString(strings.ToLower(errMsg), -1), " ") + " "
+	switch {
+	case containsPhrase(words, "while running tool", "failed to run tool", "error calling tool", "tool call failed"):
+		return retryOnTool
+	case containsPhrase(words, "exceeded maximum time", "timeout", "timed out", "deadline exceeded"):
+		return retryOnTimeout
+	case containsPhrase(words, "rate limit", "rate limited", "too many requests", "status 429", "status code 429", "overloaded"):
+		return retryOnModel
+	default:
+		return errorKindUnknown
+	}
+}
+
+var wordRegexp = regexp.MustCompile(`[a-z0-9]+`)
+
+// containsPhrase returns whether the space separated words contain one of the phrases as whole words.
+func containsPhrase(words string, phrases ...string) bool {
+	return slices.ContainsFunc(phrases, func(phrase string) bool {
+		return strings.Contains(words, " "+phrase+" ")
+	})
+}
+
+func parseDurationOrDefault(s string, def time.Duration) time.Duration {
+	if s == "" {
+		return def
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return def
+	}
+	return d
+}
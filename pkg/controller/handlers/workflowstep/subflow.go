@@ -35,6 +35,11 @@ func (h *Handler) RunSubflow(req router.Request, _ router.Response) error {
 		return err
 	}
 
+	var parent v1.WorkflowExecution
+	if err := req.Get(&parent, step.Namespace, step.Spec.WorkflowExecutionName); err != nil {
+		return err
+	}
+
 	for i, subCall := range step.Status.SubCalls {
 		if len(step.Status.RunNames) > i+1 {
 			continue
@@ -42,7 +47,9 @@ func (h *Handler) RunSubflow(req router.Request, _ router.Response) error {
 
 		wf, ok := wfs[subCall.Workflow]
 		if !ok {
-			return nil
+			step.Status.State = types.WorkflowStateError
+			step.Status.Error = fmt.Sprintf("workflow %s not found", subCall.Workflow)
+			return req.Client.Status().Update(req.Ctx, step)
 		}
 
 		wfe := &v1.WorkflowExecution{
@@ -58,6 +65,7 @@ func (h *Handler) RunSubflow(req router.Request, _ router.Response) error {
 				AfterWorkflowStepName: step.Spec.AfterWorkflowStepName,
 				WorkspaceName:         wf.Status.WorkspaceName,
 				WorkflowGeneration:    step.Spec.WorkflowGeneration,
+				DryRun:                parent.Spec.DryRun,
 			},
 		}
 
@@ -114,7 +122,7 @@ func (h *Handler) RunSubflow(req router.Request, _ router.Response) error {
 		step.Status.LastRunName = nextRunName
 	}
 
-	return nil
+	return h.checkOutputSchema(req, step)
 }
 
 func (h *Handler) getSubflowOutput(req router.Request, wfe *v1.WorkflowExecution) (string, bool, bool, error) {
@@ -128,11 +136,13 @@ func (h *Handler) getSubflowOutput(req router.Request, wfe *v1.WorkflowExecution
 		return "", false, false, err
 	}
 
-	if check.Status.State == types.WorkflowStateError && check.Status.WorkflowGeneration == wfe.Spec.WorkflowGeneration {
+	if (check.Status.State == types.WorkflowStateError || check.Status.State == types.WorkflowStateCancelled) &&
+		check.Status.WorkflowGeneration == wfe.Spec.WorkflowGeneration {
 		return check.Status.Error, true, true, nil
 	}
 
-	if check.Status.State != types.WorkflowStateComplete || check.Status.WorkflowGeneration != wfe.Spec.WorkflowGeneration {
+	if (check.Status.State != types.WorkflowStateComplete && check.Status.State != types.WorkflowStateRecovered) ||
+		check.Status.WorkflowGeneration != wfe.Spec.WorkflowGeneration {
 		return "", false, false, nil
 	}
 
@@ -0,0 +1,203 @@
+package workflowstep
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/obot-platform/nah/pkg/apply"
+	"github.com/obot-platform/nah/pkg/router"
+	"github.com/obot-platform/obot/apiclient/types"
+	"github.com/obot-platform/obot/pkg/invoke"
+	v1 "github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const switchDefaultCase = "default"
+
+// RunSwitch asks the model to classify the conversation into one of the cases of the step in a single call and then
+// runs the steps of the chosen case, or the default steps if no case applies.
+func (h *Handler) RunSwitch(req router.Request, _ router.Response) (err error) {
+	step := req.Object.(*v1.WorkflowStep)
+
+	if step.Spec.Step.Switch == nil {
+		return nil
+	}
+
+	var completeResponse bool
+	objects := []kclient.Object{}
+	defer func() {
+		apply := apply.New(req.Client)
+		if !completeResponse {
+			apply.WithNoPrune()
+		}
+		if applyErr := apply.Apply(req.Ctx, req.Object, objects...); applyErr != nil && err == nil {
+			err = applyErr
+		}
+	}()
+
+	classifyStep := h.defineClassification(step)
+	objects = append(objects, classifyStep)
+
+	if _, errorMsg, state, err := GetStateFromSteps(req.Ctx, req.Client, step.Spec.WorkflowGeneration, classifyStep); err != nil {
+		return err
+	} else if state.IsBlocked() {
+		step.Status.State = state
+		step.Status.Error = errorMsg
+		return nil
+	}
+
+	classifyRunName, caseIndex, wait, err := getSwitchResult(req, step, classifyStep)
+	if err != nil {
+		return err
+	} else if wait {
+		return nil
+	}
+
+	steps := h.defineSwitchSteps(step, classifyStep, caseIndex)
+	objects = append(objects, steps...)
+	completeResponse = true
+
+	if len(steps) == 0 {
+		step.Status.State = types.WorkflowStateComplete
+		step.Status.LastRunName = classifyRunName
+		return nil
+	}
+
+	runName, errMsg, newState, err := GetStateFromSteps(req.Ctx, req.Client, step.Spec.WorkflowGeneration, steps...)
+	if err != nil {
+		return err
+	}
+
+	if newState.IsBlocked() {
+		step.Status.State = newState
+		step.Status.Error = errMsg
+		return nil
+	}
+
+	step.Status.State = newState
+	step.Status.LastRunName = runName
+	return nil
+}
+
+// getSwitchResult returns the index of the case chosen by the classification step, or -1 for the default case, and
+// records the choice in the status of the switch step. Like getConditionResult, it assumes the classification step
+// isn't blocked.
+func getSwitchResult(req router.Request, parentStep, classifyStep *v1.WorkflowStep) (runName string, caseIndex int, wait bool, err error) {
+	var checkStep v1.WorkflowStep
+	if err := req.Client.Get(req.Ctx, router.Key(classifyStep.Namespace, classifyStep.Name), &checkStep); apierrors.IsNotFound(err) {
+		return "", 0, true, nil
+	} else if err != nil {
+		return "", 0, false, err
+	}
+
+	if checkStep.Status.State != types.WorkflowStateComplete || checkStep.Status.LastRunName == "" {
+		return "", 0, true, nil
+	}
+
+	output, err := invoke.RunOutput(req.Ctx, req.Client, classifyStep.Namespace, checkStep.Status.LastRunName)
+	if err != nil {
+		return "", 0, false, err
+	}
+
+	caseName, rationale := parseSwitchResult(output)
+	caseIndex, ok := findSwitchCase(parentStep.Spec.Step.Switch, caseName)
+	if !ok {
+		parentStep.Status.Error = fmt.Sprintf("Error evaluating switch: %s", output)
+		parentStep.Status.State = types.WorkflowStateError
+		return "", 0, true, nil
+	}
+
+	if caseIndex < 0 {
+		caseName = switchDefaultCase
+	} else {
+		caseName = parentStep.Spec.Step.Switch.Cases[caseIndex].Name
+	}
+	parentStep.Status.Switch = &v1.WorkflowStepSwitch{
+		Case:      caseName,
+		Rationale: rationale,
+	}
+
+	return checkStep.Status.LastRunName, caseIndex, false, nil
+}
+
+// parseSwitchResult reads the case and rationale from the JSON response of the model, falling back to treating the
+// whole response as the name of the case.
+func parseSwitchResult(output string) (caseName, rationale string) {
+	var result struct {
+		Case      string `json:"case"`
+		Rationale string `json:"rationale"`
+	}
+	if err := json.Unmarshal([]byte(extractJSON(output)), &result); err == nil && result.Case != "" {
+		return result.Case, result.Rationale
+	}
+	return output, ""
+}
+
+func findSwitchCase(sw *types.Switch, caseName string) (int, bool) {
+	caseName = truthyNormalize(caseName)
+	for i, c := range sw.Cases {
+		if truthyNormalize(c.Name) == caseName {
+			return i, true
+		}
+	}
+	if caseName == switchDefaultCase {
+		return -1, true
+	}
+	return 0, false
+}
+
+func toSwitchClassification(sw *types.Switch) string {
+	var sb strings.Builder
+	sb.WriteString("Choose which one of the following cases applies")
+	if sw.Condition != "" {
+		sb.WriteString(" to: ")
+		sb.WriteString(sw.Condition)
+	}
+	sb.WriteString("\n\nCASES:\n")
+	for _, c := range sw.Cases {
+		sb.WriteString("- ")
+		sb.WriteString(c.Name)
+		if c.Description != "" {
+			sb.WriteString(": ")
+			sb.WriteString(c.Description)
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString(fmt.Sprintf("\nIf none of the cases apply, choose %q. ", switchDefaultCase))
+	sb.WriteString(`Respond with only a JSON object with the name of the chosen case in the "case" field and a short ` +
+		`explanation of why it was chosen in the "rationale" field.`)
+	return sb.String()
+}
+
+func (h *Handler) defineClassification(step *v1.WorkflowStep) *v1.WorkflowStep {
+	return newChildStep(step, step.Spec.AfterWorkflowStepName, types.Step{
+		ID:   step.Spec.Step.ID + "{switch}",
+		Step: toSwitchClassification(step.Spec.Step.Switch),
+	})
+}
+
+// defineSwitchSteps defines the steps of the chosen case. The IDs of the steps are suffixed with the case so that
+// they are unique to the branch but still map back to the steps of the manifest.
+func (h *Handler) defineSwitchSteps(step, classifyStep *v1.WorkflowStep, caseIndex int) (result []kclient.Object) {
+	var (
+		steps   = step.Spec.Step.Switch.Default
+		caseKey = switchDefaultCase
+	)
+	if caseIndex >= 0 {
+		steps = step.Spec.Step.Switch.Cases[caseIndex].Steps
+		caseKey = strconv.Itoa(caseIndex)
+	}
+
+	lastStepName := classifyStep.Name
+	for _, caseStep := range steps {
+		caseStep.ID = fmt.Sprintf("%s{case=%s}", caseStep.ID, caseKey)
+		newStep := newChildStep(step, lastStepName, caseStep)
+		result = append(result, newStep)
+		lastStepName = newStep.Name
+	}
+
+	return result
+}
@@ -0,0 +1,141 @@
+package workflowstep
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/obot-platform/nah/pkg/router"
+	"github.com/obot-platform/obot/apiclient/types"
+	ktime "github.com/obot-platform/obot/pkg/gateway/time"
+	"github.com/obot-platform/obot/pkg/invoke"
+	v1 "github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var (
+	waitDateTimeLayouts = []string{"2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02 15:04:05", "2006-01-02 15:04"}
+	waitClockLayouts    = []string{"15:04", "15:04:05", "3PM", "3:04PM", "3 PM", "3:04 PM"}
+)
+
+// RunWait parks the step until the time it waits for. The time is computed once when the step starts and stored in
+// the status, so the step doesn't hold a run while it waits and resumes on time after a restart. Like an approval
+// step, a wait step passes through the run of the previous step. Dry runs don't wait.
+func (h *Handler) RunWait(req router.Request, resp router.Response) error {
+	step := req.Object.(*v1.WorkflowStep)
+
+	if step.Spec.Step.Wait == nil {
+		return nil
+	}
+
+	if step.Status.Wait == nil {
+		var wfe v1.WorkflowExecution
+		if err := req.Get(&wfe, step.Namespace, step.Spec.WorkflowExecutionName); err != nil {
+			return err
+		}
+
+		until, err := WaitUntil(&wfe, *step.Spec.Step.Wait, time.Now())
+		if err != nil {
+			step.Status.State = types.WorkflowStateError
+			step.Status.Error = fmt.Sprintf("invalid wait: %v", err)
+			return nil
+		}
+		if wfe.Spec.DryRun != nil {
+			until = time.Now()
+		}
+
+		step.Status.Wait = &v1.WorkflowStepWait{
+			Until: metav1.NewTime(until),
+		}
+	}
+
+	if remaining := time.Until(step.Status.Wait.Until.Time); remaining > 0 {
+		step.Status.State = types.WorkflowStateWaiting
+		step.Status.Error = ""
+		resp.RetryAfter(remaining)
+		return nil
+	}
+
+	return passThrough(req, step)
+}
+
+// WaitUntil returns the time a wait step started at now waits until. The duration, time and timezone of the wait can
+// reference the input of the workflow execution, like ${input.timezone}. A duration is added to now. A time is either
+// an RFC 3339 timestamp, a date and time in the timezone of the wait, or a time of day, which is the next time the
+// clock in the timezone of the wait shows it. Like the times of cron jobs, a time that the clock skips is shifted by
+// the length of the gap.
+func WaitUntil(wfe *v1.WorkflowExecution, wait types.Wait, now time.Time) (time.Time, error) {
+	duration, err := invoke.InterpolateInput(wfe, wait.Duration)
+	if err != nil {
+		return time.Time{}, err
+	}
+	until, err := invoke.InterpolateInput(wfe, wait.Until)
+	if err != nil {
+		return time.Time{}, err
+	}
+	timezone, err := invoke.InterpolateInput(wfe, wait.Timezone)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	duration, until, timezone = strings.TrimSpace(duration), strings.TrimSpace(until), strings.TrimSpace(timezone)
+
+	switch {
+	case duration != "" && until != "":
+		return time.Time{}, fmt.Errorf("only one of duration and until can be set")
+	case duration != "":
+		d, err := ktime.ParseDuration(duration)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid duration %q: %w", duration, err)
+		} else if d < 0 {
+			return time.Time{}, fmt.Errorf("invalid duration %q: must not be negative", duration)
+		}
+		return now.Add(d), nil
+	case until == "":
+		return time.Time{}, fmt.Errorf("one of duration or until is required")
+	}
+
+	loc := time.UTC
+	if timezone != "" {
+		if loc, err = time.LoadLocation(timezone); err != nil {
+			return time.Time{}, fmt.Errorf("invalid timezone %q: %w", timezone, err)
+		}
+	}
+
+	if t, err := time.Parse(time.RFC3339, until); err == nil {
+		return t, nil
+	}
+
+	for _, layout := range waitDateTimeLayouts {
+		if wall, err := time.Parse(layout, until); err == nil {
+			return inLocation(wall, loc), nil
+		}
+	}
+
+	for _, layout := range waitClockLayouts {
+		clock, err := time.Parse(layout, strings.ToUpper(until))
+		if err != nil {
+			continue
+		}
+		local := now.In(loc)
+		wall := time.Date(local.Year(), local.Month(), local.Day(), clock.Hour(), clock.Minute(), clock.Second(), 0, time.UTC)
+		if next := inLocation(wall, loc); next.After(now) {
+			return next, nil
+		}
+		return inLocation(wall.AddDate(0, 0, 1), loc), nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid time %q, must be an RFC 3339 timestamp, a date and time or a time of day", until)
+}
+
+// inLocation returns the time the clock of the location shows the date and time of wall, which is given in UTC. A time
+// that is skipped when the clocks go forward is shifted by the length of the gap, and of a time that repeats when the
+// clocks go back the first occurrence is used.
+func inLocation(wall time.Time, loc *time.Location) time.Time {
+	t := time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), 0, loc)
+	shown := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
+	if skipped := wall.Sub(shown); skipped > 0 {
+		t = t.Add(skipped)
+	}
+	return t
+}
@@ -0,0 +1,150 @@
+package workflowstep
+
+import (
+	"testing"
+	"time"
+
+	"github.com/obot-platform/obot/apiclient/types"
+	v1 "github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1"
+)
+
+func TestWaitUntil(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wait    types.Wait
+		now     time.Time
+		want    time.Time
+		wantErr bool
+	}{
+		{
+			name: "duration",
+			wait: types.Wait{Duration: "1h30m"},
+			now:  time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+			want: time.Date(2024, 1, 1, 13, 30, 0, 0, time.UTC),
+		},
+		{
+			name: "duration in days",
+			wait: types.Wait{Duration: "2d"},
+			now:  time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+			want: time.Date(2024, 1, 3, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "duration from the input",
+			input: `{"delay": "10m"}`,
+			wait:  types.Wait{Duration: "${input.delay}"},
+			now:   time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+			want:  time.Date(2024, 1, 1, 12, 10, 0, 0, time.UTC),
+		},
+		{
+			name: "RFC 3339 timestamp ignores the timezone",
+			wait: types.Wait{Until: "2024-06-01T08:00:00+02:00", Timezone: "America/New_York"},
+			now:  time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+			want: time.Date(2024, 6, 1, 6, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "date and time in the timezone",
+			wait: types.Wait{Until: "2024-06-01 08:00", Timezone: "Europe/Berlin"},
+			now:  time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+			want: time.Date(2024, 6, 1, 6, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "date and time without a timezone is in UTC",
+			wait: types.Wait{Until: "2024-06-01T08:00"},
+			now:  time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+			want: time.Date(2024, 6, 1, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "time of day later today",
+			wait: types.Wait{Until: "15:00"},
+			now:  time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+			want: time.Date(2024, 1, 1, 15, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "time of day that has passed is tomorrow",
+			wait: types.Wait{Until: "9 AM"},
+			now:  time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+			want: time.Date(2024, 1, 2, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "time of day in a timezone with a half hour offset",
+			wait: types.Wait{Until: "09:00", Timezone: "Asia/Kolkata"},
+			now:  time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			want: time.Date(2024, 1, 1, 3, 30, 0, 0, time.UTC),
+		},
+		{
+			name:  "timezone from the input",
+			input: `{"timezone": "Asia/Kathmandu"}`,
+			wait:  types.Wait{Until: "6:00 AM", Timezone: "${input.timezone}"},
+			now:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			want:  time.Date(2024, 1, 1, 0, 15, 0, 0, time.UTC),
+		},
+		{
+			name: "time of day skipped when the clocks go forward",
+			wait: types.Wait{Until: "02:30", Timezone: "America/New_York"},
+			now:  time.Date(2024, 3, 9, 17, 0, 0, 0, time.UTC),
+			want: time.Date(2024, 3, 10, 7, 30, 0, 0, time.UTC),
+		},
+		{
+			name: "time of day repeated when the clocks go back",
+			wait: types.Wait{Until: "01:30", Timezone: "America/New_York"},
+			now:  time.Date(2024, 11, 2, 16, 0, 0, 0, time.UTC),
+			want: time.Date(2024, 11, 3, 5, 30, 0, 0, time.UTC),
+		},
+		{
+			name: "time of day after the clocks go back",
+			wait: types.Wait{Until: "09:00", Timezone: "America/New_York"},
+			now:  time.Date(2024, 11, 2, 16, 0, 0, 0, time.UTC),
+			want: time.Date(2024, 11, 3, 14, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "duration and until",
+			wait:    types.Wait{Duration: "1h", Until: "15:00"},
+			wantErr: true,
+		},
+		{
+			name:    "neither duration nor until",
+			wait:    types.Wait{Timezone: "UTC"},
+			wantErr: true,
+		},
+		{
+			name:    "negative duration",
+			wait:    types.Wait{Duration: "-1h"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid timezone",
+			wait:    types.Wait{Until: "15:00", Timezone: "Mars/Olympus_Mons"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid time",
+			wait:    types.Wait{Until: "tomorrow"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wfe := &v1.WorkflowExecution{
+				Spec: v1.WorkflowExecutionSpec{
+					Input: tt.input,
+				},
+			}
+
+			got, err := WaitUntil(wfe, tt.wait, tt.now)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("WaitUntil() = %v, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("WaitUntil() error = %v", err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("WaitUntil() = %v, want %v", got.UTC(), tt.want.UTC())
+			}
+		})
+	}
+}
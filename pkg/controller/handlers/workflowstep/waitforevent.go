@@ -0,0 +1,66 @@
+package workflowstep
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/obot-platform/nah/pkg/router"
+	"github.com/obot-platform/obot/apiclient/types"
+	ktime "github.com/obot-platform/obot/pkg/gateway/time"
+	"github.com/obot-platform/obot/pkg/invoke"
+	v1 "github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// callbackRecheckInterval is how often a waitForEvent step checks for its event. Receiving the event updates the
+// workflow execution, which triggers the step right away, so this is only a fallback.
+const callbackRecheckInterval = time.Minute
+
+// waitForEvent returns whether the waitForEvent step is still waiting for its event. The step fails if the event
+// isn't received within the timeout of the step. Once the event is received, the step starts a run that completes
+// with the payload of the event. Dry runs don't wait.
+func (h *Handler) waitForEvent(req router.Request, resp router.Response, step *v1.WorkflowStep) (bool, error) {
+	var wfe v1.WorkflowExecution
+	if err := req.Get(&wfe, step.Namespace, step.Spec.WorkflowExecutionName); err != nil {
+		return false, err
+	}
+
+	if _, ok := wfe.Status.Callbacks[step.Spec.Step.ID]; ok || wfe.Spec.DryRun != nil {
+		step.Status.Wait = nil
+		return false, nil
+	}
+
+	if step.Status.Wait == nil && step.Spec.Step.WaitForEvent.Timeout != "" {
+		timeout, err := invoke.InterpolateInput(&wfe, step.Spec.Step.WaitForEvent.Timeout)
+		if err != nil {
+			step.Status.State = types.WorkflowStateError
+			step.Status.Error = fmt.Sprintf("invalid timeout: %v", err)
+			return true, nil
+		}
+		d, err := ktime.ParseDuration(timeout)
+		if err != nil || d <= 0 {
+			step.Status.State = types.WorkflowStateError
+			step.Status.Error = fmt.Sprintf("invalid timeout %q", timeout)
+			return true, nil
+		}
+		step.Status.Wait = &v1.WorkflowStepWait{
+			Until: metav1.NewTime(time.Now().Add(d)),
+		}
+	}
+
+	recheck := callbackRecheckInterval
+	if step.Status.Wait != nil {
+		remaining := time.Until(step.Status.Wait.Until.Time)
+		if remaining <= 0 {
+			step.Status.State = types.WorkflowStateError
+			step.Status.Error = fmt.Sprintf("no event received by %s", step.Status.Wait.Until.UTC().Format(time.RFC3339))
+			return true, nil
+		}
+		recheck = min(recheck, remaining)
+	}
+
+	step.Status.State = types.WorkflowStateWaiting
+	step.Status.Error = ""
+	resp.RetryAfter(recheck)
+	return true, nil
+}
@@ -131,7 +131,7 @@ func (h *Handler) defineWhile(groupIndex int, conditionStep, step *v1.WorkflowSt
 			afterStepName = lastStepName
 		}
 		loopStep.ID = fmt.Sprintf("%s{index=%d}", loopStep.ID, groupIndex)
-		newStep := NewStep(step.Namespace, step.Spec.WorkflowExecutionName, afterStepName, step.Spec.WorkflowGeneration, loopStep)
+		newStep := newChildStep(step, afterStepName, loopStep)
 		result = append(result, newStep)
 		lastStepName = newStep.Name
 	}
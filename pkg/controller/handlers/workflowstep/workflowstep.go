@@ -3,6 +3,7 @@ package workflowstep
 import (
 	"context"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/obot-platform/nah/pkg/name"
@@ -61,7 +62,7 @@ func deleteLastRuns(ctx context.Context, client kclient.Client, step *v1.Workflo
 		}
 	}
 
-	for _, run := range step.Status.RunNames {
+	for _, run := range slices.Concat(step.Status.RunNames, step.Status.OutputSchemaRunNames) {
 		if err := client.Delete(ctx, &v1.Run{
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      run,
@@ -72,8 +73,30 @@ func deleteLastRuns(ctx context.Context, client kclient.Client, step *v1.Workflo
 		}
 	}
 
+	if err := deleteAttemptRuns(ctx, client, step); err != nil {
+		return err
+	}
+
 	step.Status.LastRunName = ""
 	step.Status.RunNames = nil
+	step.Status.OutputSchemaRunNames = nil
+	step.Status.StructuredOutput = ""
+	return nil
+}
+
+// deleteAttemptRuns deletes the runs of the attempts that were retried, which are kept until now for inspection.
+func deleteAttemptRuns(ctx context.Context, client kclient.Client, step *v1.WorkflowStep) error {
+	for _, attempt := range step.Status.Attempts {
+		if err := client.Delete(ctx, &v1.Run{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      attempt.RunName,
+				Namespace: step.Namespace,
+			},
+		}); kclient.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	step.Status.Attempts = nil
 	return nil
 }
 
@@ -96,10 +119,17 @@ func (h *Handler) Preconditions(next router.Handler) router.Handler {
 func (h *Handler) checkPreconditions(req router.Request, _ router.Response) (proceed bool, err error) {
 	step := req.Object.(*v1.WorkflowStep)
 
-	if step.Status.State.IsTerminal() {
+	if step.Status.State.IsTerminal() || step.Status.State == types.WorkflowStateCancelled {
 		if !step.IsGenerationInSync() {
 			// We are rerunning, reset the state and reprocess
+			if err := deleteAttemptRuns(req.Ctx, req.Client, step); err != nil {
+				return false, err
+			}
 			step.Status.State = types.WorkflowStatePending
+			step.Status.Approval = nil
+			step.Status.Switch = nil
+			step.Status.OnError = nil
+			step.Status.Wait = nil
 			return false, nil
 		}
 		// When terminal we no longer process anything
@@ -110,6 +140,22 @@ func (h *Handler) checkPreconditions(req router.Request, _ router.Response) (pro
 	// but maybe not actually accomplished anything yet.
 	step.Status.WorkflowGeneration = step.Spec.WorkflowGeneration
 
+	var wf v1.WorkflowExecution
+	if err := req.Get(&wf, step.Namespace, step.Spec.WorkflowExecutionName); err != nil {
+		return false, kclient.IgnoreNotFound(err)
+	}
+
+	if wf.Spec.Cancel {
+		step.Status.State = types.WorkflowStateCancelled
+		step.Status.Error = ""
+		return false, nil
+	}
+
+	if step.Status.OnError != nil {
+		// The step failed and its onError steps are run by RunOnError
+		return false, nil
+	}
+
 	if step.Spec.AfterWorkflowStepName == "" {
 		// (darkness) No parents, nothing to check
 		return true, nil
@@ -131,11 +177,6 @@ func (h *Handler) checkPreconditions(req router.Request, _ router.Response) (pro
 		return false, nil
 	}
 
-	var wf v1.WorkflowExecution
-	if err := req.Get(&wf, step.Namespace, step.Spec.WorkflowExecutionName); err != nil {
-		return false, err
-	}
-
 	if matchesStepID(&parent, wf.Spec.RunUntilStep) {
 		// We are blocked because the workflow is supposed to only run until the parent step
 		step.Status.State = types.WorkflowStateBlocked
@@ -158,8 +199,14 @@ func (h *Handler) checkPreconditions(req router.Request, _ router.Response) (pro
 	}
 
 	if parent.Status.LastRunName == "" {
-		step.Status.State = types.WorkflowStateBlocked
-		return false, nil
+		// A pass-through step, like a wait or an approval, at the start of the workflow completes without a run. The
+		// step after it starts a new chain of runs. Otherwise the run of the parent is missing.
+		if fresh, err := noRunsBefore(req, &parent); err != nil {
+			return false, kclient.IgnoreNotFound(err)
+		} else if !fresh {
+			step.Status.State = types.WorkflowStateBlocked
+			return false, nil
+		}
 	}
 
 	if step.Status.State == "" {
@@ -168,6 +215,47 @@ func (h *Handler) checkPreconditions(req router.Request, _ router.Response) (pro
 	return true, nil
 }
 
+// passThrough completes a step that has no run of its own, like an approval or a forEach without items, with the run
+// of the previous step. A step at the start of the workflow has none, checkPreconditions then lets the step after it
+// start a new chain of runs.
+func passThrough(req router.Request, step *v1.WorkflowStep) error {
+	lastRunName, err := previousLastRunName(req, step)
+	if err != nil {
+		return err
+	}
+	step.Status.State = types.WorkflowStateComplete
+	step.Status.LastRunName = lastRunName
+	step.Status.Error = ""
+	return nil
+}
+
+func previousLastRunName(req router.Request, step *v1.WorkflowStep) (string, error) {
+	if step.Spec.AfterWorkflowStepName == "" {
+		return "", nil
+	}
+
+	var previousStep v1.WorkflowStep
+	if err := req.Get(&previousStep, step.Namespace, step.Spec.AfterWorkflowStepName); err != nil {
+		return "", err
+	}
+	return previousStep.Status.LastRunName, nil
+}
+
+// noRunsBefore returns whether none of the steps before the given step has a run.
+func noRunsBefore(req router.Request, step *v1.WorkflowStep) (bool, error) {
+	for step.Spec.AfterWorkflowStepName != "" {
+		var previous v1.WorkflowStep
+		if err := req.Get(&previous, step.Namespace, step.Spec.AfterWorkflowStepName); err != nil {
+			return false, err
+		}
+		if previous.Status.LastRunName != "" {
+			return false, nil
+		}
+		step = &previous
+	}
+	return true, nil
+}
+
 func normalizeStepID(stepID string) string {
 	id, _, _ := strings.Cut(stepID, "{")
 	return id
@@ -205,6 +293,10 @@ func GetStateFromSteps[T kclient.Object](ctx context.Context, client kclient.Cli
 			return "", "", types.WorkflowStateRunning, nil
 		}
 		if i == len(steps)-1 && step.Status.State == types.WorkflowStateComplete {
+			if step.Status.LastRunName == "" {
+				// The steps only passed through, there is no run and no output
+				return "", "", types.WorkflowStateComplete, nil
+			}
 			var run v1.Run
 			if err := client.Get(ctx, router.Key(step.Namespace, step.Status.LastRunName), &run); err != nil {
 				return "", "", "", err
@@ -242,3 +334,12 @@ func NewStep(namespace, workflowExecutionName, afterStepName string, generation
 		},
 	}
 }
+
+// newChildStep defines a step that is run as part of the given parent step. The forEach item and the handled error
+// of the parent are passed down so that nested steps of an iteration or an onError block see the same values.
+func newChildStep(parent *v1.WorkflowStep, afterStepName string, step types.Step) *v1.WorkflowStep {
+	newStep := NewStep(parent.Namespace, parent.Spec.WorkflowExecutionName, afterStepName, parent.Spec.WorkflowGeneration, step)
+	newStep.Spec.Item = parent.Spec.Item
+	newStep.Spec.Error = parent.Spec.Error
+	return newStep
+}
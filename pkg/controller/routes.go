@@ -12,6 +12,7 @@ import (
 	"github.com/obot-platform/obot/pkg/controller/handlers/knowledgesource"
 	"github.com/obot-platform/obot/pkg/controller/handlers/knowledgesummary"
 	"github.com/obot-platform/obot/pkg/controller/handlers/oauthapp"
+	"github.com/obot-platform/obot/pkg/controller/handlers/retention"
 	"github.com/obot-platform/obot/pkg/controller/handlers/runs"
 	"github.com/obot-platform/obot/pkg/controller/handlers/threads"
 	"github.com/obot-platform/obot/pkg/controller/handlers/toolinfo"
@@ -39,13 +40,14 @@ func (c *Controller) setupRoutes() error {
 	knowledgeset := knowledgeset.New(c.services.Invoker)
 	knowledgesource := knowledgesource.NewHandler(c.services.Invoker, c.services.GPTClient)
 	knowledgefile := knowledgefile.New(c.services.Invoker, c.services.GPTClient, c.services.KnowledgeSetIngestionLimit)
-	runs := runs.New(c.services.Invoker)
+	runs := runs.New(c.services.Invoker, c.services.RunRetention)
 	webHooks := webhook.New()
 	cronJobs := cronjob.New()
 	oauthLogins := oauthapp.NewLogin(c.services.Invoker, c.services.ServerURL)
 	knowledgesummary := knowledgesummary.NewHandler(c.services.GPTClient)
 	toolInfo := toolinfo.New(c.services.GPTClient)
 	threads := threads.NewHandler(c.services.GPTClient)
+	retention := retention.New(c.services.WorkflowRetention)
 
 	// Runs
 	root.Type(&v1.Run{}).HandlerFunc(removeOldFinalizers)
@@ -68,11 +70,13 @@ func (c *Controller) setupRoutes() error {
 
 	// Workflows
 	root.Type(&v1.Workflow{}).HandlerFunc(workflow.EnsureIDs)
+	root.Type(&v1.Workflow{}).HandlerFunc(workflow.CreateRevision)
 	root.Type(&v1.Workflow{}).HandlerFunc(workflow.CreateWorkspaceAndKnowledgeSet)
 	root.Type(&v1.Workflow{}).HandlerFunc(workflow.BackPopulateAuthStatus)
 	root.Type(&v1.Workflow{}).HandlerFunc(cleanup.Cleanup)
 	root.Type(&v1.Workflow{}).HandlerFunc(alias.AssignAlias)
 	root.Type(&v1.Workflow{}).HandlerFunc(toolInfo.SetToolInfoStatus)
+	root.Type(&v1.Workflow{}).HandlerFunc(retention.GC)
 	root.Type(&v1.Workflow{}).HandlerFunc(generationed.UpdateObservedGeneration)
 
 	// WorkflowExecutions
@@ -80,6 +84,9 @@ func (c *Controller) setupRoutes() error {
 	root.Type(&v1.WorkflowExecution{}).HandlerFunc(workflowExecution.Run)
 	root.Type(&v1.WorkflowExecution{}).HandlerFunc(workflowExecution.ReassignThread)
 
+	// WorkflowRevisions
+	root.Type(&v1.WorkflowRevision{}).HandlerFunc(cleanup.Cleanup)
+
 	// Agents
 	root.Type(&v1.Agent{}).HandlerFunc(agents.CreateWorkspaceAndKnowledgeSet)
 	root.Type(&v1.Agent{}).HandlerFunc(agents.BackPopulateAuthStatus)
@@ -169,8 +176,14 @@ func (c *Controller) setupRoutes() error {
 	running := steps.Middleware(workflowStep.Preconditions)
 	running.HandlerFunc(workflowStep.RunInvoke)
 	running.HandlerFunc(workflowStep.RunIf)
+	running.HandlerFunc(workflowStep.RunSwitch)
 	running.HandlerFunc(workflowStep.RunWhile)
+	running.HandlerFunc(workflowStep.RunParallel)
+	running.HandlerFunc(workflowStep.RunForEach)
+	running.HandlerFunc(workflowStep.RunApproval)
+	running.HandlerFunc(workflowStep.RunWait)
 	steps.HandlerFunc(workflowStep.RunSubflow)
+	steps.HandlerFunc(workflowStep.RunOnError)
 
 	// AgentAuthorizations
 	root.Type(&v1.AgentAuthorization{}).HandlerFunc(cleanup.Cleanup)
@@ -12,6 +12,7 @@ import (
 	"github.com/obot-platform/obot/pkg/alias"
 	v1 "github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1"
 	"github.com/obot-platform/obot/pkg/system"
+	"github.com/obot-platform/obot/pkg/workflowparams"
 	apierror "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	kclient "sigs.k8s.io/controller-runtime/pkg/client"
@@ -83,6 +84,18 @@ func (h *EmailHandler) dispatchEmail(ctx context.Context, email v1.EmailReceiver
 		Body    string `json:"body"`
 	}
 
+	var workflow v1.Workflow
+	if err := alias.Get(ctx, h.c, &workflow, email.Namespace, email.Spec.Workflow); err != nil {
+		return err
+	}
+
+	// The params of the workflow describe the body of the email, which then has to be a JSON object.
+	body, err := workflowparams.Validate(workflow.Spec.Manifest.InputParams, body)
+	if err != nil {
+		log.Infof("Skipping mail for %s: %v", to, err)
+		return nil
+	}
+
 	input.Type = "email"
 	input.From = from
 	input.To = to
@@ -94,11 +107,6 @@ func (h *EmailHandler) dispatchEmail(ctx context.Context, email v1.EmailReceiver
 		return fmt.Errorf("marshal input: %w", err)
 	}
 
-	var workflow v1.Workflow
-	if err = alias.Get(ctx, h.c, &workflow, email.Namespace, email.Spec.Workflow); err != nil {
-		return err
-	}
-
 	return h.c.Create(ctx, &v1.WorkflowExecution{
 		ObjectMeta: metav1.ObjectMeta{
 			GenerateName: system.WorkflowExecutionPrefix,
@@ -0,0 +1,144 @@
+package invoke
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/obot-platform/nah/pkg/router"
+	"github.com/obot-platform/obot/apiclient/types"
+	v1 "github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1"
+	apierror "k8s.io/apimachinery/pkg/api/errors"
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// CallbackURL returns the URL that receives the event a waitForEvent step of the execution waits for. The step ID is
+// the ID of the waiting step, including the suffix of its loop iteration or onError block, so that every wait has
+// its own URL.
+func CallbackURL(serverURL string, wfe *v1.WorkflowExecution, stepID string) string {
+	return fmt.Sprintf("%s/api/workflow-callbacks/%s/%s/%s?token=%s", serverURL, url.PathEscape(wfe.Namespace),
+		url.PathEscape(wfe.Name), url.PathEscape(stepID), url.QueryEscape(wfe.Status.CallbackToken))
+}
+
+var iterationRegexp = regexp.MustCompile(`index=(\d+)`)
+
+// callbacks returns the callback URLs and token of the waitForEvent steps of the execution as a JSON object for
+// ${callbacks.<id>.url} references, so that earlier steps can hand them to the system that sends the event. A
+// waitForEvent step in the body of a loop waits once per iteration, and the URL is the one of the iteration that the
+// referencing step runs in, or of the first iteration if it doesn't run in one.
+func (i *Invoker) callbacks(wfe *v1.WorkflowExecution, step *v1.WorkflowStep) (string, bool, error) {
+	iteration := "0"
+	if m := iterationRegexp.FindStringSubmatch(step.Spec.Step.ID); m != nil {
+		iteration = m[1]
+	}
+
+	result := map[string]map[string]string{}
+	if manifest := wfe.Status.WorkflowManifest; manifest != nil {
+		waits := waitForEventSteps(manifest.Steps, "")
+		if manifest.OnError != nil {
+			waits = append(waits, waitForEventSteps(manifest.OnError.Steps, "{onError}")...)
+		}
+		for _, wait := range waits {
+			instanceID := wait.id + strings.ReplaceAll(wait.suffix, "{index}", fmt.Sprintf("{index=%s}", iteration))
+			result[wait.id] = map[string]string{
+				"url":   CallbackURL(i.serverURL, wfe, instanceID),
+				"token": wfe.Status.CallbackToken,
+			}
+		}
+	}
+
+	data, err := json.Marshal(result)
+	return string(data), true, err
+}
+
+// waitForEventStep is a waitForEvent step of the manifest with the suffix that the controller adds to the ID of the
+// step when it runs it.
+type waitForEventStep struct {
+	id     string
+	suffix string
+}
+
+func waitForEventSteps(steps []types.Step, suffix string) (result []waitForEventStep) {
+	for _, step := range steps {
+		if step.WaitForEvent != nil {
+			result = append(result, waitForEventStep{id: step.ID, suffix: suffix})
+		}
+		if step.If != nil {
+			result = append(result, waitForEventSteps(step.If.Steps, "")...)
+			result = append(result, waitForEventSteps(step.If.Else, "")...)
+		}
+		if step.While != nil {
+			result = append(result, waitForEventSteps(step.While.Steps, "{index}")...)
+		}
+		if step.ForEach != nil {
+			result = append(result, waitForEventSteps(step.ForEach.Steps, "{index}")...)
+		}
+		if step.Parallel != nil {
+			for _, branch := range step.Parallel.Branches {
+				result = append(result, waitForEventSteps(branch.Steps, "")...)
+			}
+		}
+		if step.Switch != nil {
+			for i, c := range step.Switch.Cases {
+				result = append(result, waitForEventSteps(c.Steps, fmt.Sprintf("{case=%d}", i))...)
+			}
+			result = append(result, waitForEventSteps(step.Switch.Default, "{case=default}")...)
+		}
+		if step.OnError != nil {
+			// The onError steps of a step carry the ID of the failed step, including its suffix, in their own.
+			result = append(result, waitForEventSteps(step.OnError.Steps, fmt.Sprintf("{onError=%s%s}", step.ID, suffix))...)
+		}
+	}
+	return result
+}
+
+// eventStep starts the run of a waitForEvent step once its event was received. Like a tool call, the run doesn't
+// call the model or add to the chat, it is completed with the payload of the event when it is resumed.
+func (i *Invoker) eventStep(ctx context.Context, c kclient.WithWatch, step *v1.WorkflowStep, opt StepOptions) (*Response, error) {
+	var wfe v1.WorkflowExecution
+	if err := c.Get(ctx, router.Key(step.Namespace, step.Spec.WorkflowExecutionName), &wfe); err != nil {
+		return nil, err
+	}
+
+	agent, err := i.toAgentFromStep(ctx, c, step)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.Agent(ctx, c, &agent, "", Options{
+		ThreadName:            wfe.Status.ThreadName,
+		WorkflowStepName:      step.Name,
+		WorkflowStepID:        step.Spec.Step.ID,
+		WorkflowExecutionName: wfe.Name,
+		PreviousRunName:       opt.PreviousRunName,
+		ToolCall:              true,
+		ThreadCredentialScope: wfe.Spec.ThreadCredentialScope,
+	})
+}
+
+// eventPayload returns the payload of the event received for the step of the run, if the run belongs to a
+// waitForEvent step.
+func eventPayload(ctx context.Context, c kclient.Client, run *v1.Run) (string, bool, error) {
+	if !run.Spec.ToolCall || run.Spec.WorkflowStepName == "" {
+		return "", false, nil
+	}
+
+	var step v1.WorkflowStep
+	if err := c.Get(ctx, router.Key(run.Namespace, run.Spec.WorkflowStepName), &step); apierror.IsNotFound(err) {
+		return "", false, nil
+	} else if err != nil {
+		return "", false, err
+	} else if step.Spec.Step.WaitForEvent == nil {
+		return "", false, nil
+	}
+
+	var wfe v1.WorkflowExecution
+	if err := c.Get(ctx, router.Key(run.Namespace, step.Spec.WorkflowExecutionName), &wfe); err != nil {
+		return "", false, err
+	}
+
+	return wfe.Status.Callbacks[step.Spec.Step.ID].Payload, true, nil
+}
@@ -0,0 +1,217 @@
+package invoke
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gptscript-ai/go-gptscript"
+	"github.com/obot-platform/nah/pkg/router"
+	"github.com/obot-platform/obot/apiclient/types"
+	"github.com/obot-platform/obot/pkg/gz"
+	v1 "github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1"
+	apierror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	dryRunIndexRegexp  = regexp.MustCompile(`index=(\d+)`)
+	dryRunSuffixRegexp = regexp.MustCompile(`\{([^}]*)}`)
+)
+
+// getDryRun returns the dry run settings of the workflow execution of the run, or nil if the run isn't part of a
+// dry run.
+func getDryRun(ctx context.Context, c kclient.Client, run *v1.Run) (*v1.WorkflowDryRun, error) {
+	if run.Spec.WorkflowExecutionName == "" || isEphemeral(run) {
+		return nil, nil
+	}
+
+	var wfe v1.WorkflowExecution
+	if err := c.Get(ctx, router.Key(run.Namespace, run.Spec.WorkflowExecutionName), &wfe); apierror.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	dryRun := wfe.Spec.DryRun
+	if dryRun != nil && run.Spec.ToolCall && dryRun.CallTools {
+		return nil, nil
+	}
+	return dryRun, nil
+}
+
+// dryRun completes the run with the response from the fixtures instead of calling the model.
+func (i *Invoker) dryRun(ctx context.Context, c kclient.Client, thread *v1.Thread, run *v1.Run, dryRun *v1.WorkflowDryRun) error {
+	output, respErr := dryRunResponse(ctx, c, run, dryRun)
+	return i.completeRun(ctx, c, thread, run, output, respErr)
+}
+
+// completeRun finishes the run with the given output or error without calling the model, and saves the state the
+// same way a real run would so that the workflow step handlers can't tell the difference.
+func (i *Invoker) completeRun(ctx context.Context, c kclient.Client, thread *v1.Thread, run *v1.Run, output string, respErr error) error {
+	runState := v1.RunState{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      run.Name,
+			Namespace: run.Namespace,
+		},
+		Spec: v1.RunStateSpec{
+			ThreadName: run.Spec.ThreadName,
+			Done:       true,
+		},
+	}
+	if respErr != nil {
+		runState.Spec.Error = respErr.Error()
+	} else {
+		data, err := gz.Compress(output)
+		if err != nil {
+			return err
+		}
+		runState.Spec.Output = data
+	}
+	if err := c.Create(ctx, &runState); err != nil && !apierror.IsAlreadyExists(err) {
+		return err
+	}
+
+	if respErr == nil {
+		i.events.SubmitProgress(run, types.Progress{
+			RunID:   run.Name,
+			Time:    types.NewTime(time.Now()),
+			Content: output,
+		})
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		if err := c.Get(ctx, router.Key(run.Namespace, run.Name), run); err != nil {
+			return err
+		}
+
+		switch {
+		case respErr != nil:
+			run.Status.State = gptscript.Error
+			run.Status.Error = respErr.Error()
+		case run.Spec.ToolCall:
+			run.Status.State = gptscript.Finished
+		default:
+			run.Status.State = gptscript.Continue
+		}
+
+		if respErr == nil {
+			run.Status.SubCall = toSubCall(output)
+			run.Status.TaskResult = toTaskResult(output)
+			if run.Status.SubCall == nil && run.Status.TaskResult == nil {
+				run.Status.Output = output
+				if len(run.Status.Output) > runOutputMaxLength {
+					run.Status.Output = run.Status.Output[:runOutputMaxLength]
+				}
+			}
+		}
+		run.Status.EndTime = metav1.Now()
+		return c.Status().Update(ctx, run)
+	})
+	if err != nil {
+		return err
+	}
+
+	if thread.Spec.SystemTask {
+		return nil
+	}
+
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		if err := c.Get(ctx, router.Key(thread.Namespace, thread.Name), thread); err != nil {
+			return err
+		}
+		if thread.Status.LastRunName == run.Name {
+			return nil
+		}
+		thread.Status.CurrentRunName = ""
+		thread.Status.LastRunName = run.Name
+		thread.Status.LastRunState = run.Status.State
+		return c.Status().Update(ctx, thread)
+	})
+}
+
+// dryRunResponse returns the fixture for the step of the run. Responses keyed by the exact step ID are used first,
+// then the ones keyed by the ID of the step in the manifest, where the iteration of a loop picks the response.
+func dryRunResponse(ctx context.Context, c kclient.Client, run *v1.Run, dryRun *v1.WorkflowDryRun) (string, error) {
+	stepID := run.Spec.WorkflowStepID
+
+	continuations, err := countContinuations(ctx, c, run)
+	if err != nil {
+		return "", err
+	}
+
+	responses, ok := dryRun.Responses[stepID]
+	index := continuations
+	if !ok {
+		responses, ok = dryRun.Responses[baseStepID(stepID)]
+		if m := dryRunIndexRegexp.FindStringSubmatch(stepID); m != nil {
+			n, _ := strconv.Atoi(m[1])
+			index += n
+		}
+	}
+
+	switch {
+	case index < len(responses):
+		return responses[index], nil
+	case continuations > 0:
+		// Echo what the step was continued with, like the output of a subflow, once the responses run out.
+		return run.Spec.Input, nil
+	case len(responses) > 0:
+		return responses[len(responses)-1], nil
+	case !ok && run.Spec.ToolCall:
+		return "", nil
+	case !ok:
+		return "", fmt.Errorf("no dry run response for step %s", stepID)
+	}
+	return "", nil
+}
+
+// countContinuations returns the number of runs of the same step that came before the run, which is the number of
+// times the step has been continued after a subflow or an output correction.
+func countContinuations(ctx context.Context, c kclient.Client, run *v1.Run) (int, error) {
+	var (
+		count    int
+		previous = run.Spec.PreviousRunName
+	)
+	for previous != "" {
+		var prevRun v1.Run
+		if err := c.Get(ctx, router.Key(run.Namespace, previous), &prevRun); apierror.IsNotFound(err) {
+			break
+		} else if err != nil {
+			return 0, err
+		}
+		if prevRun.Spec.WorkflowStepName != run.Spec.WorkflowStepName {
+			break
+		}
+		count++
+		previous = prevRun.Spec.PreviousRunName
+	}
+	return count, nil
+}
+
+// baseStepID strips the iteration and the switch case from the ID of a step created by another step, keeping the
+// kind of the step, so "check{condition,index=2}" becomes "check{condition}" and "item{index=2}" becomes "item".
+func baseStepID(stepID string) string {
+	base, _, ok := strings.Cut(stepID, "{")
+	if !ok {
+		return stepID
+	}
+
+	var kinds []string
+	for _, group := range dryRunSuffixRegexp.FindAllStringSubmatch(stepID, -1) {
+		for _, part := range strings.Split(group[1], ",") {
+			if part != "" && !strings.Contains(part, "=") {
+				kinds = append(kinds, part)
+			}
+		}
+	}
+	if len(kinds) == 0 {
+		return base
+	}
+	return base + "{" + strings.Join(kinds, ",") + "}"
+}
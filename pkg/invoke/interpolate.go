@@ -0,0 +1,92 @@
+package invoke
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	v1 "github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1"
+)
+
+var interpolateRegexp = regexp.MustCompile(`\$\{\s*([A-Za-z0-9_.\-]+)\s*}`)
+
+// interpolate replaces every ${name} or ${name.field} reference in text with the value returned by lookup. The
+// field path is applied to the value as JSON. References to names that lookup doesn't know are left as is. A
+// reference that can't be resolved, like a field of a value that isn't JSON, returns an ErrStepConfig.
+func interpolate(text string, lookup func(name string) (string, bool, error)) (string, error) {
+	var retErr error
+	result := interpolateRegexp.ReplaceAllStringFunc(text, func(match string) string {
+		if retErr != nil {
+			return match
+		}
+
+		ref := interpolateRegexp.FindStringSubmatch(match)[1]
+		name, path, _ := strings.Cut(ref, ".")
+		value, ok, err := lookup(name)
+		if err != nil {
+			retErr = err
+			return match
+		} else if !ok {
+			return match
+		}
+
+		value, err = JSONField(value, path)
+		if err != nil {
+			retErr = ErrStepConfig{Message: fmt.Sprintf("failed to resolve %s: %v", match, err)}
+			return match
+		}
+		return value
+	})
+	return result, retErr
+}
+
+// JSONField returns the field at the dot separated path of the given JSON data. String values are returned as is
+// and all other values as JSON. Null values and the fields below them, like the fields of the output of a step that
+// didn't run, are empty. An empty path returns the data unchanged.
+func JSONField(data, path string) (string, error) {
+	if path == "" {
+		return data, nil
+	}
+
+	var value any
+	if err := json.Unmarshal([]byte(data), &value); err != nil {
+		return "", fmt.Errorf("value is not JSON: %w", err)
+	}
+
+	for _, key := range strings.Split(path, ".") {
+		if value == nil {
+			return "", nil
+		}
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return "", fmt.Errorf("field %s not found", path)
+		}
+		if value, ok = obj[key]; !ok {
+			return "", fmt.Errorf("field %s not found", path)
+		}
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	}
+	result, err := json.Marshal(value)
+	return string(result), err
+}
+
+// InterpolateInput replaces the ${input} and ${params} references in text with the input of the workflow execution,
+// for settings of steps that are resolved by the controller instead of being sent to the model.
+func InterpolateInput(wfe *v1.WorkflowExecution, text string) (string, error) {
+	return interpolate(text, func(name string) (string, bool, error) {
+		switch name {
+		case "input":
+			return wfe.Spec.Input, true, nil
+		case "params":
+			return paramValues(wfe)
+		}
+		return "", false, nil
+	})
+}
@@ -161,6 +161,7 @@ type Options struct {
 	WorkflowExecutionName string
 	PreviousRunName       string
 	ForceNoResume         bool
+	ToolCall              bool
 	CreateThread          bool
 	ThreadCredentialScope *bool
 	UserUID               string
@@ -168,7 +169,8 @@ type Options struct {
 }
 
 func (i *Invoker) getChatState(ctx context.Context, c kclient.Client, run *v1.Run) (result, lastThreadName string, _ error) {
-	if run.Spec.PreviousRunName == "" {
+	if run.Spec.PreviousRunName == "" || run.Spec.ToolCall {
+		// Tools called directly don't chat, so there is no state to resume.
 		return "", "", nil
 	}
 
@@ -303,7 +305,7 @@ func (i *Invoker) Agent(ctx context.Context, c kclient.WithWatch, agent *v1.Agen
 		return nil, err
 	}
 
-	if len(agent.Spec.Manifest.Params) == 0 {
+	if len(agent.Spec.Manifest.Params) == 0 && !opt.ToolCall {
 		data := map[string]any{}
 		if err := json.Unmarshal([]byte(input), &data); err == nil {
 			if msg, ok := data[render.DefaultAgentParams[0]].(string); ok && len(data) == 1 && msg != "" {
@@ -322,6 +324,7 @@ func (i *Invoker) Agent(ctx context.Context, c kclient.WithWatch, agent *v1.Agen
 		WorkflowExecutionName: opt.WorkflowExecutionName,
 		PreviousRunName:       opt.PreviousRunName,
 		ForceNoResume:         opt.ForceNoResume,
+		ToolCall:              opt.ToolCall,
 	})
 }
 
@@ -342,6 +345,7 @@ type runOptions struct {
 	WorkflowStepID        string
 	PreviousRunName       string
 	ForceNoResume         bool
+	ToolCall              bool
 	Env                   []string
 	CredentialContextIDs  []string
 	Timeout               time.Duration
@@ -382,6 +386,7 @@ func (i *Invoker) createRun(ctx context.Context, c kclient.WithWatch, thread *v1
 			WorkflowStepName:      opts.WorkflowStepName,
 			WorkflowStepID:        opts.WorkflowStepID,
 			PreviousRunName:       previousRunName,
+			ToolCall:              opts.ToolCall,
 			Input:                 input,
 			Tool:                  string(toolData),
 			Env:                   opts.Env,
@@ -482,6 +487,18 @@ func (i *Invoker) Resume(ctx context.Context, c kclient.WithWatch, thread *v1.Th
 		}
 	}
 
+	if dryRun, err := getDryRun(ctx, c, run); err != nil {
+		return err
+	} else if dryRun != nil {
+		return i.dryRun(ctx, c, thread, run, dryRun)
+	}
+
+	if payload, ok, err := eventPayload(ctx, c, run); err != nil {
+		return err
+	} else if ok {
+		return i.completeRun(ctx, c, thread, run, payload, nil)
+	}
+
 	chatState, prevThreadName, err := i.getChatState(ctx, c, run)
 	if err != nil {
 		return err
@@ -703,6 +720,11 @@ func (i *Invoker) doSaveState(ctx context.Context, c kclient.Client, prevThreadN
 		runChanged = true
 	}
 
+	if usage := runUsage(runResp.Calls()); run.Status.Usage != usage {
+		run.Status.Usage = usage
+		runChanged = true
+	}
+
 	var final bool
 	switch state {
 	case gptscript.Error:
@@ -1007,6 +1029,16 @@ func watchThreadAbort(ctx context.Context, c kclient.WithWatch, thread *v1.Threa
 	})
 }
 
+// runUsage adds up the tokens used by the LLM calls of a run.
+func runUsage(calls gptscript.CallFrames) (usage v1.TokenUsage) {
+	for _, call := range calls {
+		usage.PromptTokens += int64(call.Usage.PromptTokens)
+		usage.CompletionTokens += int64(call.Usage.CompletionTokens)
+		usage.TotalTokens += int64(call.Usage.TotalTokens)
+	}
+	return usage
+}
+
 func timeoutAfter(ctx context.Context, cancel func(err error), d time.Duration) {
 	select {
 	case <-ctx.Done():
@@ -0,0 +1,242 @@
+package invoke
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/obot-platform/obot/apiclient/types"
+	v1 "github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1"
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// referencedSteps returns the IDs of the steps referenced as ${steps.<id>.output} in the given texts.
+func referencedSteps(texts ...string) (result []string) {
+	for _, text := range texts {
+		for _, match := range interpolateRegexp.FindAllStringSubmatch(text, -1) {
+			name, path, _ := strings.Cut(match[1], ".")
+			if name != "steps" {
+				continue
+			}
+			id, _, _ := strings.Cut(path, ".")
+			if id != "" && !slices.Contains(result, id) {
+				result = append(result, id)
+			}
+		}
+	}
+	return result
+}
+
+// stepOutputs returns the outputs of the given steps of the execution as a JSON object for ${steps.<id>.output}
+// references. If a step ran more than once, like in a loop, the output of the latest run is used, and steps that
+// didn't run, like the steps of a branch that wasn't taken, have a null output, so that their output and its fields
+// resolve to an empty string. Structured outputs and outputs that are JSON are included as JSON so that their fields
+// can be referenced.
+func stepOutputs(ctx context.Context, c kclient.Client, step *v1.WorkflowStep, ids []string) (string, error) {
+	latest, err := latestSteps(ctx, c, step, ids)
+	if err != nil {
+		return "", err
+	}
+
+	result := make(map[string]map[string]any, len(ids))
+	for _, id := range ids {
+		var output any
+		if other, ok := latest[id]; ok {
+			if output, err = stepOutput(ctx, c, other); err != nil {
+				return "", err
+			}
+		}
+		result[id] = map[string]any{
+			"output": output,
+		}
+	}
+
+	data, err := json.Marshal(result)
+	return string(data), err
+}
+
+// StepOutput returns the output of the step with the given ID the same way as ${steps.<id>.output} references
+// resolve it, and whether the step completed.
+func StepOutput(ctx context.Context, c kclient.Client, step *v1.WorkflowStep, id string) (string, bool, error) {
+	latest, err := latestSteps(ctx, c, step, []string{id})
+	if err != nil {
+		return "", false, err
+	}
+
+	other, ok := latest[id]
+	if !ok {
+		return "", false, nil
+	}
+
+	output, err := stepOutput(ctx, c, other)
+	if err != nil {
+		return "", false, err
+	}
+	if raw, ok := output.(json.RawMessage); ok {
+		return string(raw), true, nil
+	}
+	return output.(string), true, nil
+}
+
+// latestSteps returns the latest completed step of the execution for each of the given IDs.
+func latestSteps(ctx context.Context, c kclient.Client, step *v1.WorkflowStep, ids []string) (map[string]v1.WorkflowStep, error) {
+	var steps v1.WorkflowStepList
+	if err := c.List(ctx, &steps, kclient.InNamespace(step.Namespace), kclient.MatchingFields{
+		"spec.workflowExecutionName": step.Spec.WorkflowExecutionName,
+	}); err != nil {
+		return nil, err
+	}
+
+	latest := make(map[string]v1.WorkflowStep, len(ids))
+	for _, other := range steps.Items {
+		id := stepLookupID(other.Spec.Step.ID)
+		if other.Spec.WorkflowGeneration != step.Spec.WorkflowGeneration || other.Status.State != types.WorkflowStateComplete ||
+			isControlStep(other.Spec.Step.ID) || !slices.Contains(ids, id) {
+			continue
+		}
+		if current, ok := latest[id]; ok && current.CreationTimestamp.After(other.CreationTimestamp.Time) {
+			continue
+		}
+		latest[id] = other
+	}
+	return latest, nil
+}
+
+func stepOutput(ctx context.Context, c kclient.Client, step v1.WorkflowStep) (any, error) {
+	if step.Status.StructuredOutput != "" {
+		return json.RawMessage(step.Status.StructuredOutput), nil
+	}
+
+	output, err := RunOutput(ctx, c, step.Namespace, step.Status.LastRunName)
+	if err != nil {
+		return nil, err
+	}
+
+	if trimmed := strings.TrimSpace(output); (strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[")) &&
+		json.Valid([]byte(trimmed)) {
+		return json.RawMessage(trimmed), nil
+	}
+	return output, nil
+}
+
+// isControlStep returns whether the step was created by another step to evaluate a condition, classify a switch or
+// join parallel branches. These steps share the ID of the step that created them, but their output isn't the output
+// of that step.
+func isControlStep(stepID string) bool {
+	return strings.Contains(stepID, "{condition") || strings.Contains(stepID, "{switch}") || strings.Contains(stepID, "{join}")
+}
+
+// ReferenceChecker checks the ${steps.<id>.output}, ${callbacks.<id>.url}, ${input.<name>} and ${params.<name>}
+// references in the texts of a workflow, so that a typo in a reference is reported when the workflow is saved instead
+// of being passed to the model as is. Input and params references are only checked if the workflow declares params.
+type ReferenceChecker struct {
+	ids, events, params map[string]struct{}
+}
+
+func NewReferenceChecker(manifest types.WorkflowManifest) *ReferenceChecker {
+	r := &ReferenceChecker{
+		ids:    map[string]struct{}{},
+		events: map[string]struct{}{},
+		params: make(map[string]struct{}, len(manifest.InputParams)),
+	}
+
+	collectStepIDs(r.ids, manifest.Steps)
+	for _, id := range waitForEventSteps(manifest.Steps) {
+		r.events[id] = struct{}{}
+	}
+	if manifest.OnError != nil {
+		collectStepIDs(r.ids, manifest.OnError.Steps)
+		for _, id := range waitForEventSteps(manifest.OnError.Steps) {
+			r.events[id] = struct{}{}
+		}
+	}
+
+	for _, param := range manifest.InputParams {
+		r.params[param.Name] = struct{}{}
+	}
+
+	return r
+}
+
+// Check returns an error for each invalid reference in text.
+func (r *ReferenceChecker) Check(text string) (errs []error) {
+	for _, match := range interpolateRegexp.FindAllStringSubmatch(text, -1) {
+		if err := r.check(match[1]); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", match[0], err))
+		}
+	}
+	return errs
+}
+
+func (r *ReferenceChecker) check(ref string) error {
+	name, path, _ := strings.Cut(ref, ".")
+	switch name {
+	case "steps":
+		id, field, _ := strings.Cut(path, ".")
+		if id == "" {
+			return fmt.Errorf("missing step ID, expected ${steps.<id>.output}")
+		}
+		if _, ok := r.ids[id]; !ok {
+			return fmt.Errorf("unknown step %q", id)
+		}
+		if field != "output" && !strings.HasPrefix(field, "output.") {
+			return fmt.Errorf("unknown field %q of step %s, expected ${steps.%s.output}", field, id, id)
+		}
+	case "callbacks":
+		id, field, _ := strings.Cut(path, ".")
+		if _, ok := r.events[id]; !ok {
+			return fmt.Errorf("unknown waitForEvent step %q", id)
+		}
+		if field != "url" && field != "token" {
+			return fmt.Errorf("unknown field %q of callback %s, expected ${callbacks.%s.url} or ${callbacks.%s.token}", field, id, id, id)
+		}
+	case "input", "params":
+		param, _, _ := strings.Cut(path, ".")
+		if param == "" || len(r.params) == 0 {
+			return nil
+		}
+		if _, ok := r.params[param]; !ok {
+			return fmt.Errorf("unknown param %q", param)
+		}
+	}
+	return nil
+}
+
+func collectStepIDs(ids map[string]struct{}, steps []types.Step) {
+	for _, step := range steps {
+		ids[step.ID] = struct{}{}
+		for _, children := range childSteps(step) {
+			collectStepIDs(ids, children)
+		}
+	}
+}
+
+// childSteps returns the nested lists of steps of a step.
+func childSteps(step types.Step) (result [][]types.Step) {
+	if step.If != nil {
+		result = append(result, step.If.Steps, step.If.Else)
+	}
+	if step.While != nil {
+		result = append(result, step.While.Steps)
+	}
+	if step.ForEach != nil {
+		result = append(result, step.ForEach.Steps)
+	}
+	if step.Parallel != nil {
+		for _, branch := range step.Parallel.Branches {
+			result = append(result, branch.Steps)
+		}
+	}
+	if step.Switch != nil {
+		for _, c := range step.Switch.Cases {
+			result = append(result, c.Steps)
+		}
+		result = append(result, step.Switch.Default)
+	}
+	if step.OnError != nil {
+		result = append(result, step.OnError.Steps)
+	}
+	return result
+}
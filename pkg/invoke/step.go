@@ -4,11 +4,18 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
 
 	"github.com/obot-platform/nah/pkg/router"
 	"github.com/obot-platform/obot/apiclient/types"
+	"github.com/obot-platform/obot/pkg/gz"
 	"github.com/obot-platform/obot/pkg/render"
 	v1 "github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1"
+	"github.com/obot-platform/obot/pkg/workflowparams"
+	apierror "k8s.io/apimachinery/pkg/api/errors"
 	kclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -17,37 +24,162 @@ type StepOptions struct {
 	Continue        *string
 }
 
+// ErrStepConfig is returned for a step that can't be invoked because of its configuration, like a tool that doesn't
+// exist. Invoking the step again fails the same way, so the step should fail instead of being retried.
+type ErrStepConfig struct {
+	Message string
+}
+
+func (e ErrStepConfig) Error() string {
+	return e.Message
+}
+
 func (i *Invoker) Step(ctx context.Context, c kclient.WithWatch, step *v1.WorkflowStep, opt StepOptions) (*Response, error) {
+	if step.Spec.Step.Tool != nil && step.Spec.Step.Tool.Name != "" {
+		return i.toolCallStep(ctx, c, step, opt)
+	}
+	if step.Spec.Step.WaitForEvent != nil {
+		return i.eventStep(ctx, c, step, opt)
+	}
+
 	agent, err := i.toAgentFromStep(ctx, c, step)
 	if err != nil {
 		return nil, err
 	}
 
-	input, err := i.getInput(step)
-	if err != nil {
+	var wfe v1.WorkflowExecution
+	if err := c.Get(ctx, router.Key(step.Namespace, step.Spec.WorkflowExecutionName), &wfe); err != nil {
 		return nil, err
 	}
 
+	var input string
 	if opt.Continue != nil {
+		// The run continues the chat of the step, so its prompt isn't needed and its references aren't resolved again.
 		input = *opt.Continue
+	} else {
+		if input, err = i.getInput(ctx, c, step, &wfe, opt.PreviousRunName); err != nil {
+			return nil, err
+		}
+		if step.Spec.Step.Template == nil || step.Spec.Step.Template.Name == "" {
+			// Tools called directly don't add to the chat, so pass their output along with the input.
+			toolOutputs, err := toolCallOutputs(ctx, c, step.Namespace, opt.PreviousRunName)
+			if err != nil {
+				return nil, err
+			}
+			if len(toolOutputs) > 0 {
+				input = strings.Join(toolOutputs, "\n\n") + "\n\n" + input
+			}
+		}
 	}
 
+	return i.Agent(ctx, c, &agent, input, Options{
+		ThreadName:            wfe.Status.ThreadName,
+		WorkflowStepName:      step.Name,
+		WorkflowStepID:        step.Spec.Step.ID,
+		WorkflowExecutionName: wfe.Name,
+		PreviousRunName:       opt.PreviousRunName,
+		ForceNoResume:         opt.PreviousRunName == "",
+		ThreadCredentialScope: wfe.Spec.ThreadCredentialScope,
+	})
+}
+
+// toolCallStep calls the tool of the step directly with its arguments, without an LLM. The run is chained to the
+// previous run like any other step, but it doesn't resume or add to the chat.
+func (i *Invoker) toolCallStep(ctx context.Context, c kclient.WithWatch, step *v1.WorkflowStep, opt StepOptions) (*Response, error) {
 	var wfe v1.WorkflowExecution
 	if err := c.Get(ctx, router.Key(step.Namespace, step.Spec.WorkflowExecutionName), &wfe); err != nil {
 		return nil, err
 	}
 
+	agent, err := i.toAgentFromStep(ctx, c, step)
+	if err != nil {
+		return nil, err
+	}
+
+	toolName, _, err := render.Tool(ctx, c, step.Namespace, step.Spec.Step.Tool.Name)
+	if err != nil {
+		return nil, err
+	} else if toolName == "" {
+		return nil, ErrStepConfig{Message: fmt.Sprintf("tool %s not found", step.Spec.Step.Tool.Name)}
+	}
+
+	agent.Spec.Manifest.Prompt = "#!sys.call " + toolName
+	agent.Spec.Manifest.Tools = []string{step.Spec.Step.Tool.Name}
+	agent.Spec.Manifest.Agents = nil
+	agent.Spec.Manifest.Workflows = nil
+	agent.Spec.InputFilters = nil
+	agent.Spec.SystemTools = nil
+
+	var (
+		args   = make(map[string]string, len(step.Spec.Step.Tool.Args))
+		lookup = i.stepLookup(ctx, c, step, &wfe, opt.PreviousRunName, slices.Collect(maps.Values(step.Spec.Step.Tool.Args)))
+	)
+	for k, v := range step.Spec.Step.Tool.Args {
+		args[k], err = interpolate(v, lookup)
+		if err != nil {
+			return nil, fmt.Errorf("invalid argument %s of tool %s: %w", k, step.Spec.Step.Tool.Name, err)
+		}
+	}
+
+	input, err := toStringArgs(args)
+	if err != nil {
+		return nil, err
+	}
+
 	return i.Agent(ctx, c, &agent, input, Options{
 		ThreadName:            wfe.Status.ThreadName,
 		WorkflowStepName:      step.Name,
 		WorkflowStepID:        step.Spec.Step.ID,
 		WorkflowExecutionName: wfe.Name,
 		PreviousRunName:       opt.PreviousRunName,
-		ForceNoResume:         opt.PreviousRunName == "",
+		ToolCall:              true,
 		ThreadCredentialScope: wfe.Spec.ThreadCredentialScope,
 	})
 }
 
+// toolCallOutputs returns the outputs of the tool calls that directly precede the given run, oldest first.
+func toolCallOutputs(ctx context.Context, c kclient.Client, namespace, runName string) (result []string, _ error) {
+	for runName != "" {
+		var run v1.Run
+		if err := c.Get(ctx, router.Key(namespace, runName), &run); err != nil {
+			return nil, err
+		}
+		if !run.Spec.ToolCall {
+			break
+		}
+
+		output, err := RunOutput(ctx, c, namespace, run.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append([]string{fmt.Sprintf("OUTPUT OF TOOL CALL %s:\n%s", run.Spec.WorkflowStepID, output)}, result...)
+		runName = run.Spec.PreviousRunName
+	}
+	return result, nil
+}
+
+// RunOutput returns the full output of a run, the output on the run status is truncated.
+func RunOutput(ctx context.Context, c kclient.Client, namespace, runName string) (string, error) {
+	if runName == "" {
+		return "", nil
+	}
+
+	var runState v1.RunState
+	if err := c.Get(ctx, router.Key(namespace, runName), &runState); err == nil && len(runState.Spec.Output) > 0 {
+		var output string
+		return output, gz.Decompress(&output, runState.Spec.Output)
+	} else if err != nil && !apierror.IsNotFound(err) {
+		return "", err
+	}
+
+	var run v1.Run
+	if err := c.Get(ctx, router.Key(namespace, runName), &run); err != nil {
+		return "", err
+	}
+	return run.Status.Output, nil
+}
+
 func (i *Invoker) toAgentFromStep(ctx context.Context, c kclient.Client, step *v1.WorkflowStep) (v1.Agent, error) {
 	var (
 		wf  v1.Workflow
@@ -85,11 +217,112 @@ func toStringArgs(args map[string]string) (string, error) {
 	return string(data), err
 }
 
-func (i *Invoker) getInput(step *v1.WorkflowStep) (string, error) {
+// stepLookup resolves the references that steps can interpolate in their prompts and arguments. The outputs of the
+// steps referenced in texts are only fetched if they are used.
+func (i *Invoker) stepLookup(ctx context.Context, c kclient.Client, step *v1.WorkflowStep, wfe *v1.WorkflowExecution, previousRunName string, texts []string) func(string) (string, bool, error) {
+	outputs := sync.OnceValues(func() (string, error) {
+		return stepOutputs(ctx, c, step, referencedSteps(texts...))
+	})
+	return func(name string) (string, bool, error) {
+		switch name {
+		case "input":
+			return wfe.Spec.Input, true, nil
+		case "item":
+			return step.Spec.Item, true, nil
+		case "error":
+			return step.Spec.Error, true, nil
+		case "params":
+			return paramValues(wfe)
+		case "previous":
+			output, err := RunOutput(ctx, c, step.Namespace, previousRunName)
+			return output, true, err
+		case "steps":
+			value, err := outputs()
+			return value, true, err
+		case "callbacks":
+			return i.callbacks(wfe, step)
+		}
+		return "", false, nil
+	}
+}
+
+func (i *Invoker) getInput(ctx context.Context, c kclient.Client, step *v1.WorkflowStep, wfe *v1.WorkflowExecution, previousRunName string) (string, error) {
 	if step.Spec.Step.Template != nil && step.Spec.Step.Template.Name != "" {
-		return toStringArgs(step.Spec.Step.Template.Args)
+		lookup := i.stepLookup(ctx, c, step, wfe, previousRunName, slices.Collect(maps.Values(step.Spec.Step.Template.Args)))
+		args := make(map[string]string, len(step.Spec.Step.Template.Args))
+		for k, v := range step.Spec.Step.Template.Args {
+			var err error
+			if args[k], err = interpolate(v, lookup); err != nil {
+				return "", fmt.Errorf("invalid argument %s of template %s: %w", k, step.Spec.Step.Template.Name, err)
+			}
+		}
+		return toStringArgs(withItemArg(args, step.Spec.Item))
 	} else if step.Spec.Step.Step != "" {
-		return step.Spec.Step.Step, nil
+		input, err := interpolate(step.Spec.Step.Step, i.stepLookup(ctx, c, step, wfe, previousRunName, []string{step.Spec.Step.Step}))
+		if err != nil {
+			return "", fmt.Errorf("invalid step %s: %w", step.Spec.Step.ID, err)
+		}
+		if step.Spec.Item != "" {
+			input = fmt.Sprintf("CURRENT ITEM:\n%s\n\n%s", step.Spec.Item, input)
+		}
+		if step.Spec.Error != "" {
+			input = fmt.Sprintf("ERROR TO HANDLE:\n%s\n\n%s", step.Spec.Error, input)
+		}
+		if step.Spec.Step.OutputSchema != nil && len(step.Spec.Step.OutputSchema.Schema) > 0 {
+			input += "\n\nRespond with only a JSON document that matches this JSON schema:\n" + string(step.Spec.Step.OutputSchema.Schema)
+		}
+		return input, nil
 	}
 	return "", nil
 }
+
+// withItemArg passes the forEach item to a template as the "item" argument, unless the template sets it itself.
+func withItemArg(args map[string]string, item string) map[string]string {
+	if item == "" {
+		return args
+	}
+	if _, ok := args["item"]; ok {
+		return args
+	}
+	result := make(map[string]string, len(args)+1)
+	for k, v := range args {
+		result[k] = v
+	}
+	result["item"] = item
+	return result
+}
+
+// ParamsInput returns the part of the input of the execution that the params of the workflow describe: the payload of
+// a webhook, the body of an email or otherwise the whole input.
+func ParamsInput(wfe *v1.WorkflowExecution) string {
+	if wfe.Spec.WebhookName == "" && wfe.Spec.EmailReceiverName == "" {
+		return wfe.Spec.Input
+	}
+
+	var envelope struct {
+		Payload string `json:"payload"`
+		Body    string `json:"body"`
+	}
+	if err := json.Unmarshal([]byte(wfe.Spec.Input), &envelope); err != nil {
+		return wfe.Spec.Input
+	}
+	if wfe.Spec.WebhookName != "" {
+		return envelope.Payload
+	}
+	return envelope.Body
+}
+
+// paramValues returns the params of the workflow execution for ${params.name} references. The input of an execution
+// started by a webhook or an email wraps the payload or the body that the params describe.
+func paramValues(wfe *v1.WorkflowExecution) (string, bool, error) {
+	var params []types.WorkflowParam
+	if wfe.Status.WorkflowManifest != nil {
+		params = wfe.Status.WorkflowManifest.InputParams
+	}
+
+	values, err := workflowparams.Values(params, ParamsInput(wfe))
+	if err != nil {
+		return "", true, ErrStepConfig{Message: err.Error()}
+	}
+	return values, true, nil
+}
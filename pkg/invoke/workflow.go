@@ -26,6 +26,7 @@ type WorkflowOptions struct {
 	WorkflowExecutionName string
 	ThreadCredentialScope *bool
 	Events                bool
+	Budget                *types.WorkflowBudget
 }
 
 func (i *Invoker) startWorkflow(ctx context.Context, c kclient.WithWatch, wf *v1.Workflow, input string, opt WorkflowOptions) (*v1.WorkflowExecution, *v1.Thread, error) {
@@ -40,6 +41,7 @@ func (i *Invoker) startWorkflow(ctx context.Context, c kclient.WithWatch, wf *v1
 			Input:                 input,
 			WorkflowName:          wf.Name,
 			ThreadCredentialScope: opt.ThreadCredentialScope,
+			Budget:                opt.Budget,
 		},
 	}
 
@@ -235,6 +237,8 @@ func (i *Invoker) rerunThread(ctx context.Context, c kclient.WithWatch, wf *v1.W
 
 	wfe.Spec.WorkflowGeneration++
 	wfe.Spec.RunUntilStep = stepID
+	wfe.Spec.Cancel = false
+	wfe.Spec.CancelledBy = ""
 	return &wfe, &thread, c.Update(ctx, &wfe)
 }
 
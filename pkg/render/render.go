@@ -273,7 +273,7 @@ func addAgentTools(ctx context.Context, db kclient.Client, agent *v1.Agent, main
 		return mainTool, otherTools, nil
 	}
 
-	agents, err := agentsByName(ctx, db, agent.Namespace)
+	agents, err := AgentByName(ctx, db, agent.Namespace)
 	if err != nil {
 		return mainTool, otherTools, err
 	}
@@ -346,7 +346,7 @@ func oauthAppsByName(ctx context.Context, c kclient.Client, namespace string) (m
 	return result, nil
 }
 
-func agentsByName(ctx context.Context, db kclient.Client, namespace string) (map[string]v1.Agent, error) {
+func AgentByName(ctx context.Context, db kclient.Client, namespace string) (map[string]v1.Agent, error) {
 	var agents v1.AgentList
 	err := db.List(ctx, &agents, &kclient.ListOptions{
 		Namespace: namespace,
@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/adrg/xdg"
 	"github.com/gptscript-ai/go-gptscript"
@@ -20,16 +21,19 @@ import (
 	"github.com/obot-platform/nah/pkg/apply"
 	"github.com/obot-platform/nah/pkg/leader"
 	"github.com/obot-platform/nah/pkg/router"
+	types2 "github.com/obot-platform/obot/apiclient/types"
 	"github.com/obot-platform/obot/pkg/api/authn"
 	"github.com/obot-platform/obot/pkg/api/authz"
 	"github.com/obot-platform/obot/pkg/api/server"
 	"github.com/obot-platform/obot/pkg/bootstrap"
+	"github.com/obot-platform/obot/pkg/controller/handlers/retention"
 	"github.com/obot-platform/obot/pkg/credstores"
 	"github.com/obot-platform/obot/pkg/events"
 	"github.com/obot-platform/obot/pkg/gateway/client"
 	"github.com/obot-platform/obot/pkg/gateway/db"
 	gserver "github.com/obot-platform/obot/pkg/gateway/server"
 	"github.com/obot-platform/obot/pkg/gateway/server/dispatcher"
+	ktime "github.com/obot-platform/obot/pkg/gateway/time"
 	"github.com/obot-platform/obot/pkg/gateway/types"
 	"github.com/obot-platform/obot/pkg/invoke"
 	"github.com/obot-platform/obot/pkg/jwt"
@@ -67,6 +71,12 @@ type Config struct {
 	KnowledgeSetIngestionLimit int      `usage:"The maximum number of files to ingest into a knowledge set" default:"3000" env:"OBOT_KNOWLEDGESET_INGESTION_LIMIT" name:"knowledge-set-ingestion-limit"`
 	EnableAuthentication       bool     `usage:"Enable authentication" default:"false"`
 	AuthAdminEmails            []string `usage:"Emails of admin users"`
+	RunRetention               string   `usage:"How long to keep finished runs of system tasks" default:"12h" env:"OBOT_RUN_RETENTION"`
+
+	// Workflow execution retention, workflows can override each of these in their manifest
+	WorkflowExecutionKeepLast     int    `usage:"The number of finished executions to keep per workflow, 0 keeps all" env:"OBOT_WORKFLOW_EXECUTION_KEEP_LAST"`
+	WorkflowExecutionMaxAge       string `usage:"Delete finished workflow executions older than this, like 30d" env:"OBOT_WORKFLOW_EXECUTION_MAX_AGE"`
+	WorkflowExecutionFailedMaxAge string `usage:"Delete failed workflow executions older than this instead of the max age, like 90d" env:"OBOT_WORKFLOW_EXECUTION_FAILED_MAX_AGE"`
 
 	// Sendgrid webhook
 	SendgridWebhookUsername string `usage:"The username for the sendgrid webhook to authenticate with"`
@@ -97,6 +107,8 @@ type Services struct {
 	Bootstrapper               *bootstrap.Bootstrap
 	KnowledgeSetIngestionLimit int
 	SupportDocker              bool
+	RunRetention               time.Duration
+	WorkflowRetention          types2.WorkflowRetention
 
 	// Use basic auth for sendgrid webhook, if being set
 	SendgridWebhookUsername string
@@ -207,6 +219,20 @@ func New(ctx context.Context, config Config) (*Services, error) {
 		config.ToolRegistries = []string{"github.com/obot-platform/tools"}
 	}
 
+	runRetention, err := ktime.ParseDuration(config.RunRetention)
+	if err != nil {
+		return nil, fmt.Errorf("invalid run retention %q: %w", config.RunRetention, err)
+	}
+
+	workflowRetention := types2.WorkflowRetention{
+		KeepLast:     config.WorkflowExecutionKeepLast,
+		MaxAge:       config.WorkflowExecutionMaxAge,
+		FailedMaxAge: config.WorkflowExecutionFailedMaxAge,
+	}
+	if err := retention.Validate(&workflowRetention); err != nil {
+		return nil, fmt.Errorf("invalid workflow execution retention: %w", err)
+	}
+
 	credStore, credStoreEnv, err := credstores.Init(ctx, config.ToolRegistries, config.DSN, credstores.Options{
 		AWSKMSKeyARN:         config.AWSKMSKeyARN,
 		EncryptionConfigFile: config.EncryptionConfigFile,
@@ -384,6 +410,8 @@ func New(ctx context.Context, config Config) (*Services, error) {
 		KnowledgeSetIngestionLimit: config.KnowledgeSetIngestionLimit,
 		EmailServerName:            config.EmailServerName,
 		SupportDocker:              config.Docker,
+		RunRetention:               runRetention,
+		WorkflowRetention:          workflowRetention,
 		SendgridWebhookUsername:    config.SendgridWebhookUsername,
 		SendgridWebhookPassword:    config.SendgridWebhookPassword,
 		ProxyManager:               proxyManager,
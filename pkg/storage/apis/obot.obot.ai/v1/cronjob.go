@@ -41,6 +41,7 @@ func (*CronJob) GetColumns() [][]string {
 		{"Name", "Name"},
 		{"Workflow", "Spec.Workflow"},
 		{"Schedule", "Spec.Schedule"},
+		{"Suspended", "Spec.Suspend"},
 		{"Last Success", "{{ago .Status.LastSuccessfulRunCompleted}}"},
 		{"Last Run", "{{ago .Status.LastRunStartedAt}}"},
 		{"Created", "{{ago .CreationTimestamp}}"},
@@ -65,6 +66,20 @@ type CronJobSpec struct {
 type CronJobStatus struct {
 	LastRunStartedAt           *metav1.Time `json:"lastRunStartedAt,omitempty"`
 	LastSuccessfulRunCompleted *metav1.Time `json:"lastSuccessfulRunCompleted,omitempty"`
+	// LastScheduledTime is the last scheduled time that was handled, whether it started a run or not.
+	LastScheduledTime *metav1.Time `json:"lastScheduledTime,omitempty"`
+	// SkippedRuns are the most recent scheduled times that didn't start a run and the runs that were replaced by a
+	// newer one, oldest first.
+	SkippedRuns []CronJobSkippedRun `json:"skippedRuns,omitempty"`
+	// NextRuns are the next scheduled times of the cron job, empty while it is suspended.
+	NextRuns []metav1.Time `json:"nextRuns,omitempty"`
+}
+
+type CronJobSkippedRun struct {
+	ScheduledTime metav1.Time `json:"scheduledTime,omitempty"`
+	// WorkflowExecutionName is the execution that was cancelled to make room for a newer run, if any.
+	WorkflowExecutionName string `json:"workflowExecutionName,omitempty"`
+	Reason                string `json:"reason,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -80,6 +80,8 @@ type RunSpec struct {
 	CredentialContextIDs  []string                `json:"credentialContextIDs,omitempty"`
 	DefaultModel          string                  `json:"defaultModel,omitempty"`
 	Timeout               metav1.Duration         `json:"timeout,omitempty"`
+	// ToolCall is set when the run calls a tool directly instead of chatting with an LLM.
+	ToolCall bool `json:"toolCall,omitempty"`
 }
 
 func (in *Run) DeleteRefs() []Ref {
@@ -98,6 +100,22 @@ type RunStatus struct {
 	Error      string                   `json:"error,omitempty"`
 	SubCall    *SubCall                 `json:"subCall,omitempty"`
 	TaskResult *TaskResult              `json:"taskResult,omitempty"`
+	// Usage is the number of tokens used by the LLM calls of the run so far.
+	Usage TokenUsage `json:"usage,omitempty"`
+}
+
+type TokenUsage struct {
+	PromptTokens     int64 `json:"promptTokens,omitempty"`
+	CompletionTokens int64 `json:"completionTokens,omitempty"`
+	TotalTokens      int64 `json:"totalTokens,omitempty"`
+}
+
+func (in TokenUsage) Add(other TokenUsage) TokenUsage {
+	return TokenUsage{
+		PromptTokens:     in.PromptTokens + other.PromptTokens,
+		CompletionTokens: in.CompletionTokens + other.CompletionTokens,
+		TotalTokens:      in.TotalTokens + other.TotalTokens,
+	}
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -39,6 +39,8 @@ func AddToSchemeWithGV(scheme *runtime.Scheme, schemeGroupVersion schema.GroupVe
 		&WorkflowList{},
 		&WorkflowExecution{},
 		&WorkflowExecutionList{},
+		&WorkflowRevision{},
+		&WorkflowRevisionList{},
 		&WorkflowStep{},
 		&WorkflowStepList{},
 		&KnowledgeSummary{},
@@ -105,6 +105,10 @@ type WorkflowStatus struct {
 	AuthStatus         map[string]types.OAuthAppLoginAuthStatus `json:"authStatus,omitempty"`
 	ToolInfo           map[string]types.ToolInfo                `json:"toolInfo,omitempty"`
 	ObservedGeneration int64                                    `json:"observedGeneration,omitempty"`
+	// Revision is the number of the latest revision of the manifest.
+	Revision int64 `json:"revision,omitempty"`
+	// RevisionGeneration is the generation of the workflow that the latest revision was checked against.
+	RevisionGeneration int64 `json:"revisionGeneration,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
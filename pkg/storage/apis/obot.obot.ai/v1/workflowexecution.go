@@ -31,12 +31,16 @@ func (in *WorkflowExecution) Get(field string) string {
 			return in.Spec.ThreadName
 		case "spec.webhookName":
 			return in.Spec.WebhookName
+		case "spec.webhookDeliveryKey":
+			return in.Spec.WebhookDeliveryKey
 		case "spec.cronJobName":
 			return in.Spec.CronJobName
 		case "spec.workflowName":
 			return in.Spec.WorkflowName
 		case "spec.parentRunName":
 			return in.Spec.ParentRunName
+		case "spec.parentThreadName":
+			return in.Spec.ParentThreadName
 		}
 	}
 
@@ -47,9 +51,11 @@ func (in *WorkflowExecution) FieldNames() []string {
 	return []string{
 		"spec.threadName",
 		"spec.webhookName",
+		"spec.webhookDeliveryKey",
 		"spec.cronJobName",
 		"spec.workflowName",
 		"spec.parentRunName",
+		"spec.parentThreadName",
 	}
 }
 
@@ -79,6 +85,24 @@ type WorkflowExecutionSpec struct {
 	WorkflowGeneration    int64  `json:"workflowGeneration,omitempty"`
 	RunUntilStep          string `json:"runUntilStep,omitempty"`
 	ThreadCredentialScope *bool  `json:"threadCredentialScope,omitempty"`
+	// Cancel stops the execution, its in-flight runs and its subflows.
+	Cancel bool `json:"cancel,omitempty"`
+	// CancelledBy is the user that cancelled the execution.
+	CancelledBy string `json:"cancelledBy,omitempty"`
+	// DryRun replaces the model responses of the steps with fixtures. Subflows inherit it from the parent execution.
+	DryRun *WorkflowDryRun `json:"dryRun,omitempty"`
+	// Budget overrides the limits of the budget of the workflow for this execution.
+	Budget *types.WorkflowBudget `json:"budget,omitempty"`
+	// WebhookDeliveryKey is the hash of the idempotency key of the webhook delivery that started this execution.
+	WebhookDeliveryKey string `json:"webhookDeliveryKey,omitempty"`
+}
+
+type WorkflowDryRun struct {
+	// Responses are the responses of the steps by step ID. The Nth response is used for the Nth iteration of a loop
+	// or the Nth continuation of a step, and the last response once they run out.
+	Responses map[string][]string `json:"responses,omitempty"`
+	// CallTools calls the tools of tool steps instead of using the responses.
+	CallTools bool `json:"callTools,omitempty"`
 }
 
 func (in *WorkflowExecution) DeleteRefs() []Ref {
@@ -96,6 +120,29 @@ type WorkflowExecutionStatus struct {
 	WorkflowManifest   *types.WorkflowManifest `json:"workflowManifest,omitempty"`
 	EndTime            *metav1.Time            `json:"endTime,omitempty"`
 	WorkflowGeneration int64                   `json:"workflowGeneration,omitempty"`
+	// StructuredOutput is the output parsed as JSON, set when the workflow has an output schema.
+	StructuredOutput string `json:"structuredOutput,omitempty"`
+	// WorkflowRevision is the revision of the workflow that WorkflowManifest was loaded from.
+	WorkflowRevision int64 `json:"workflowRevision,omitempty"`
+	// OnError is the state of the onError steps of the workflow, set once the execution failed.
+	OnError *WorkflowOnErrorStatus `json:"onError,omitempty"`
+	// CallbackToken authenticates the callbacks that the waitForEvent steps of the execution wait for.
+	CallbackToken string `json:"callbackToken,omitempty"`
+	// Callbacks are the callbacks received for the waitForEvent steps of the execution by the ID of the step
+	// instance, which includes the loop iteration, switch case or onError suffix of the step.
+	Callbacks map[string]WorkflowCallback `json:"callbacks,omitempty"`
+	// Usage is the number of tokens used by the runs of the execution and its subflows.
+	Usage TokenUsage `json:"usage,omitempty"`
+	// RunUsage is the usage of every run and subflow of the execution by UID. Reruns delete runs and subflows, and
+	// recreate subflows under the same name, so their usage is kept here and never removed, which keeps Usage from
+	// dropping.
+	RunUsage map[string]TokenUsage `json:"runUsage,omitempty"`
+}
+
+type WorkflowCallback struct {
+	// Payload is the body of the callback, which becomes the output of the step.
+	Payload    string      `json:"payload,omitempty"`
+	ReceivedAt metav1.Time `json:"receivedAt,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -0,0 +1,76 @@
+package v1
+
+import (
+	"github.com/obot-platform/nah/pkg/fields"
+	"github.com/obot-platform/obot/apiclient/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var (
+	_ fields.Fields = (*WorkflowRevision)(nil)
+	_ DeleteRefs    = (*WorkflowRevision)(nil)
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// WorkflowRevision is an immutable copy of the manifest of a workflow. A new revision is created for every change to
+// the manifest, and executions run the revision that was current when they started.
+type WorkflowRevision struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   WorkflowRevisionSpec   `json:"spec,omitempty"`
+	Status WorkflowRevisionStatus `json:"status,omitempty"`
+}
+
+func (in *WorkflowRevision) Has(field string) bool {
+	return in.Get(field) != ""
+}
+
+func (in *WorkflowRevision) Get(field string) string {
+	if in != nil {
+		switch field {
+		case "spec.workflowName":
+			return in.Spec.WorkflowName
+		}
+	}
+
+	return ""
+}
+
+func (in *WorkflowRevision) FieldNames() []string {
+	return []string{"spec.workflowName"}
+}
+
+func (in *WorkflowRevision) GetColumns() [][]string {
+	return [][]string{
+		{"Name", "Name"},
+		{"Workflow", "Spec.WorkflowName"},
+		{"Revision", "Spec.Revision"},
+		{"Created", "{{ago .CreationTimestamp}}"},
+	}
+}
+
+func (in *WorkflowRevision) DeleteRefs() []Ref {
+	return []Ref{
+		{ObjType: &Workflow{}, Name: in.Spec.WorkflowName},
+	}
+}
+
+type WorkflowRevisionSpec struct {
+	WorkflowName string                 `json:"workflowName,omitempty"`
+	Revision     int64                  `json:"revision,omitempty"`
+	Manifest     types.WorkflowManifest `json:"manifest,omitempty"`
+}
+
+type WorkflowRevisionStatus struct {
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type WorkflowRevisionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []WorkflowRevision `json:"items"`
+}
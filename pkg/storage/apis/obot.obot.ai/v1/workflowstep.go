@@ -60,6 +60,10 @@ type WorkflowStepSpec struct {
 	Step                  types.Step `json:"step,omitempty"`
 	WorkflowExecutionName string     `json:"workflowExecutionName,omitempty"`
 	WorkflowGeneration    int64      `json:"workflowGeneration,omitempty"`
+	// Item is the forEach item this step is run for, if any.
+	Item string `json:"item,omitempty"`
+	// Error is the error handled by this step, set for the steps of an onError block.
+	Error string `json:"error,omitempty"`
 }
 
 func (in *WorkflowStep) DeleteRefs() []Ref {
@@ -68,9 +72,12 @@ func (in *WorkflowStep) DeleteRefs() []Ref {
 		{ObjType: &Run{}, Name: in.Status.LastRunName},
 		{ObjType: &Thread{}, Name: in.Status.ThreadName},
 	}
-	for _, run := range in.Status.RunNames {
+	for _, run := range slices.Concat(in.Status.RunNames, in.Status.OutputSchemaRunNames) {
 		refs = append(refs, Ref{ObjType: &Run{}, Name: run})
 	}
+	for _, attempt := range in.Status.Attempts {
+		refs = append(refs, Ref{ObjType: &Run{}, Name: attempt.RunName})
+	}
 	return refs
 }
 
@@ -82,6 +89,20 @@ type WorkflowStepStatus struct {
 	ThreadName         string              `json:"threadName,omitempty"`
 	RunNames           []string            `json:"runNames,omitempty"`
 	LastRunName        string              `json:"lastRunName,omitempty"`
+	// Attempts records every finished attempt of this step, including the ones that were retried.
+	Attempts []WorkflowStepAttempt `json:"attempts,omitempty"`
+	// Approval is the state of the approval requested by an approval step.
+	Approval *WorkflowStepApproval `json:"approval,omitempty"`
+	// OutputSchemaRunNames are the runs that asked the model to correct an output that didn't match the output schema.
+	OutputSchemaRunNames []string `json:"outputSchemaRunNames,omitempty"`
+	// StructuredOutput is the output parsed as JSON, set when the step has an output schema.
+	StructuredOutput string `json:"structuredOutput,omitempty"`
+	// Switch is the case chosen by a switch step.
+	Switch *WorkflowStepSwitch `json:"switch,omitempty"`
+	// OnError is the state of the onError steps of the step, set once the step failed.
+	OnError *WorkflowOnErrorStatus `json:"onError,omitempty"`
+	// Wait is the time a wait step resumes at.
+	Wait *WorkflowStepWait `json:"wait,omitempty"`
 }
 
 func (in WorkflowStepStatus) FirstRun() string {
@@ -95,6 +116,40 @@ func (in WorkflowStepStatus) HasRunsSet() bool {
 	return in.LastRunName != "" || len(in.RunNames) > 0
 }
 
+type WorkflowStepAttempt struct {
+	RunName string      `json:"runName,omitempty"`
+	Error   string      `json:"error,omitempty"`
+	EndTime metav1.Time `json:"endTime,omitempty"`
+}
+
+const (
+	ApprovalDecisionApproved = "approved"
+	ApprovalDecisionRejected = "rejected"
+)
+
+type WorkflowStepApproval struct {
+	RequestedAt metav1.Time  `json:"requestedAt,omitempty"`
+	Decision    string       `json:"decision,omitempty"`
+	DecidedBy   string       `json:"decidedBy,omitempty"`
+	DecidedAt   *metav1.Time `json:"decidedAt,omitempty"`
+	Comment     string       `json:"comment,omitempty"`
+}
+
+type WorkflowOnErrorStatus struct {
+	// Error is the error that the onError steps handle.
+	Error string `json:"error,omitempty"`
+	// Handled is set once the onError steps finished.
+	Handled bool `json:"handled,omitempty"`
+	// Recovered is set if the onError steps finished and marked the failure as recovered.
+	Recovered bool `json:"recovered,omitempty"`
+}
+
+type WorkflowStepSwitch struct {
+	// Case is the name of the chosen case, or "default".
+	Case      string `json:"case,omitempty"`
+	Rationale string `json:"rationale,omitempty"`
+}
+
 type SubCall struct {
 	Type     string `json:"type,omitempty"`
 	Workflow string `json:"workflow,omitempty"`
@@ -115,3 +170,8 @@ type WorkflowStepList struct {
 
 	Items []WorkflowStep `json:"items"`
 }
+
+type WorkflowStepWait struct {
+	// Until is when the step stops waiting, computed once when the step starts so that it survives restarts.
+	Until metav1.Time `json:"until,omitempty"`
+}
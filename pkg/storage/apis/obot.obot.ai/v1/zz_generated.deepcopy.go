@@ -362,6 +362,22 @@ func (in *CronJobList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CronJobSkippedRun) DeepCopyInto(out *CronJobSkippedRun) {
+	*out = *in
+	in.ScheduledTime.DeepCopyInto(&out.ScheduledTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CronJobSkippedRun.
+func (in *CronJobSkippedRun) DeepCopy() *CronJobSkippedRun {
+	if in == nil {
+		return nil
+	}
+	out := new(CronJobSkippedRun)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CronJobSpec) DeepCopyInto(out *CronJobSpec) {
 	*out = *in
@@ -389,6 +405,24 @@ func (in *CronJobStatus) DeepCopyInto(out *CronJobStatus) {
 		in, out := &in.LastSuccessfulRunCompleted, &out.LastSuccessfulRunCompleted
 		*out = (*in).DeepCopy()
 	}
+	if in.LastScheduledTime != nil {
+		in, out := &in.LastScheduledTime, &out.LastScheduledTime
+		*out = (*in).DeepCopy()
+	}
+	if in.SkippedRuns != nil {
+		in, out := &in.SkippedRuns, &out.SkippedRuns
+		*out = make([]CronJobSkippedRun, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NextRuns != nil {
+		in, out := &in.NextRuns, &out.NextRuns
+		*out = make([]metav1.Time, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CronJobStatus.
@@ -1454,6 +1488,7 @@ func (in *RunStatus) DeepCopyInto(out *RunStatus) {
 		*out = new(TaskResult)
 		**out = **in
 	}
+	out.Usage = in.Usage
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RunStatus.
@@ -1601,6 +1636,21 @@ func (in *ThreadStatus) DeepCopy() *ThreadStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TokenUsage) DeepCopyInto(out *TokenUsage) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TokenUsage.
+func (in *TokenUsage) DeepCopy() *TokenUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(TokenUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Tool) DeepCopyInto(out *Tool) {
 	*out = *in
@@ -1962,6 +2012,53 @@ func (in *Workflow) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkflowCallback) DeepCopyInto(out *WorkflowCallback) {
+	*out = *in
+	in.ReceivedAt.DeepCopyInto(&out.ReceivedAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkflowCallback.
+func (in *WorkflowCallback) DeepCopy() *WorkflowCallback {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkflowCallback)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkflowDryRun) DeepCopyInto(out *WorkflowDryRun) {
+	*out = *in
+	if in.Responses != nil {
+		in, out := &in.Responses, &out.Responses
+		*out = make(map[string][]string, len(*in))
+		for key, val := range *in {
+			var outVal []string
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = make([]string, len(*in))
+				copy(*out, *in)
+			}
+			(*out)[key] = outVal
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkflowDryRun.
+func (in *WorkflowDryRun) DeepCopy() *WorkflowDryRun {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkflowDryRun)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WorkflowExecution) DeepCopyInto(out *WorkflowExecution) {
 	*out = *in
@@ -2029,6 +2126,16 @@ func (in *WorkflowExecutionSpec) DeepCopyInto(out *WorkflowExecutionSpec) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.DryRun != nil {
+		in, out := &in.DryRun, &out.DryRun
+		*out = new(WorkflowDryRun)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Budget != nil {
+		in, out := &in.Budget, &out.Budget
+		*out = new(types.WorkflowBudget)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkflowExecutionSpec.
@@ -2053,6 +2160,26 @@ func (in *WorkflowExecutionStatus) DeepCopyInto(out *WorkflowExecutionStatus) {
 		in, out := &in.EndTime, &out.EndTime
 		*out = (*in).DeepCopy()
 	}
+	if in.OnError != nil {
+		in, out := &in.OnError, &out.OnError
+		*out = new(WorkflowOnErrorStatus)
+		**out = **in
+	}
+	if in.Callbacks != nil {
+		in, out := &in.Callbacks, &out.Callbacks
+		*out = make(map[string]WorkflowCallback, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	out.Usage = in.Usage
+	if in.RunUsage != nil {
+		in, out := &in.RunUsage, &out.RunUsage
+		*out = make(map[string]TokenUsage, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkflowExecutionStatus.
@@ -2097,6 +2224,111 @@ func (in *WorkflowList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkflowOnErrorStatus) DeepCopyInto(out *WorkflowOnErrorStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkflowOnErrorStatus.
+func (in *WorkflowOnErrorStatus) DeepCopy() *WorkflowOnErrorStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkflowOnErrorStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkflowRevision) DeepCopyInto(out *WorkflowRevision) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkflowRevision.
+func (in *WorkflowRevision) DeepCopy() *WorkflowRevision {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkflowRevision)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *WorkflowRevision) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkflowRevisionList) DeepCopyInto(out *WorkflowRevisionList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]WorkflowRevision, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkflowRevisionList.
+func (in *WorkflowRevisionList) DeepCopy() *WorkflowRevisionList {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkflowRevisionList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *WorkflowRevisionList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkflowRevisionSpec) DeepCopyInto(out *WorkflowRevisionSpec) {
+	*out = *in
+	in.Manifest.DeepCopyInto(&out.Manifest)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkflowRevisionSpec.
+func (in *WorkflowRevisionSpec) DeepCopy() *WorkflowRevisionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkflowRevisionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkflowRevisionStatus) DeepCopyInto(out *WorkflowRevisionStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkflowRevisionStatus.
+func (in *WorkflowRevisionStatus) DeepCopy() *WorkflowRevisionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkflowRevisionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WorkflowSpec) DeepCopyInto(out *WorkflowSpec) {
 	*out = *in
@@ -2179,6 +2411,42 @@ func (in *WorkflowStep) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkflowStepApproval) DeepCopyInto(out *WorkflowStepApproval) {
+	*out = *in
+	in.RequestedAt.DeepCopyInto(&out.RequestedAt)
+	if in.DecidedAt != nil {
+		in, out := &in.DecidedAt, &out.DecidedAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkflowStepApproval.
+func (in *WorkflowStepApproval) DeepCopy() *WorkflowStepApproval {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkflowStepApproval)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkflowStepAttempt) DeepCopyInto(out *WorkflowStepAttempt) {
+	*out = *in
+	in.EndTime.DeepCopyInto(&out.EndTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkflowStepAttempt.
+func (in *WorkflowStepAttempt) DeepCopy() *WorkflowStepAttempt {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkflowStepAttempt)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WorkflowStepList) DeepCopyInto(out *WorkflowStepList) {
 	*out = *in
@@ -2240,6 +2508,38 @@ func (in *WorkflowStepStatus) DeepCopyInto(out *WorkflowStepStatus) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Attempts != nil {
+		in, out := &in.Attempts, &out.Attempts
+		*out = make([]WorkflowStepAttempt, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Approval != nil {
+		in, out := &in.Approval, &out.Approval
+		*out = new(WorkflowStepApproval)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OutputSchemaRunNames != nil {
+		in, out := &in.OutputSchemaRunNames, &out.OutputSchemaRunNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Switch != nil {
+		in, out := &in.Switch, &out.Switch
+		*out = new(WorkflowStepSwitch)
+		**out = **in
+	}
+	if in.OnError != nil {
+		in, out := &in.OnError, &out.OnError
+		*out = new(WorkflowOnErrorStatus)
+		**out = **in
+	}
+	if in.Wait != nil {
+		in, out := &in.Wait, &out.Wait
+		*out = new(WorkflowStepWait)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkflowStepStatus.
@@ -2252,6 +2552,37 @@ func (in *WorkflowStepStatus) DeepCopy() *WorkflowStepStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkflowStepSwitch) DeepCopyInto(out *WorkflowStepSwitch) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkflowStepSwitch.
+func (in *WorkflowStepSwitch) DeepCopy() *WorkflowStepSwitch {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkflowStepSwitch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkflowStepWait) DeepCopyInto(out *WorkflowStepWait) {
+	*out = *in
+	in.Until.DeepCopyInto(&out.Until)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkflowStepWait.
+func (in *WorkflowStepWait) DeepCopy() *WorkflowStepWait {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkflowStepWait)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Workspace) DeepCopyInto(out *Workspace) {
 	*out = *in
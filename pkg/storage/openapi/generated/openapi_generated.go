@@ -105,8 +105,10 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"github.com/obot-platform/obot/apiclient/types.User":                                      schema_obot_platform_obot_apiclient_types_User(ref),
 		"github.com/obot-platform/obot/apiclient/types.UserList":                                  schema_obot_platform_obot_apiclient_types_UserList(ref),
 		"github.com/obot-platform/obot/apiclient/types.Webhook":                                   schema_obot_platform_obot_apiclient_types_Webhook(ref),
+		"github.com/obot-platform/obot/apiclient/types.WebhookIdempotency":                        schema_obot_platform_obot_apiclient_types_WebhookIdempotency(ref),
 		"github.com/obot-platform/obot/apiclient/types.WebhookList":                               schema_obot_platform_obot_apiclient_types_WebhookList(ref),
 		"github.com/obot-platform/obot/apiclient/types.WebhookManifest":                           schema_obot_platform_obot_apiclient_types_WebhookManifest(ref),
+		"github.com/obot-platform/obot/apiclient/types.WebhookSignature":                          schema_obot_platform_obot_apiclient_types_WebhookSignature(ref),
 		"github.com/obot-platform/obot/apiclient/types.WebsiteCrawlingConfig":                     schema_obot_platform_obot_apiclient_types_WebsiteCrawlingConfig(ref),
 		"github.com/obot-platform/obot/apiclient/types.While":                                     schema_obot_platform_obot_apiclient_types_While(ref),
 		"github.com/obot-platform/obot/apiclient/types.Workflow":                                  schema_obot_platform_obot_apiclient_types_Workflow(ref),
@@ -128,6 +130,7 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.AliasSpec":                schema_storage_apis_obotobotai_v1_AliasSpec(ref),
 		"github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.CronJob":                  schema_storage_apis_obotobotai_v1_CronJob(ref),
 		"github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.CronJobList":              schema_storage_apis_obotobotai_v1_CronJobList(ref),
+		"github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.CronJobSkippedRun":        schema_storage_apis_obotobotai_v1_CronJobSkippedRun(ref),
 		"github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.CronJobSpec":              schema_storage_apis_obotobotai_v1_CronJobSpec(ref),
 		"github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.CronJobStatus":            schema_storage_apis_obotobotai_v1_CronJobStatus(ref),
 		"github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.DefaultModelAlias":        schema_storage_apis_obotobotai_v1_DefaultModelAlias(ref),
@@ -181,6 +184,7 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.ThreadList":               schema_storage_apis_obotobotai_v1_ThreadList(ref),
 		"github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.ThreadSpec":               schema_storage_apis_obotobotai_v1_ThreadSpec(ref),
 		"github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.ThreadStatus":             schema_storage_apis_obotobotai_v1_ThreadStatus(ref),
+		"github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.TokenUsage":               schema_storage_apis_obotobotai_v1_TokenUsage(ref),
 		"github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.Tool":                     schema_storage_apis_obotobotai_v1_Tool(ref),
 		"github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.ToolList":                 schema_storage_apis_obotobotai_v1_ToolList(ref),
 		"github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.ToolReference":            schema_storage_apis_obotobotai_v1_ToolReference(ref),
@@ -195,17 +199,28 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.WebhookSpec":              schema_storage_apis_obotobotai_v1_WebhookSpec(ref),
 		"github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.WebhookStatus":            schema_storage_apis_obotobotai_v1_WebhookStatus(ref),
 		"github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.Workflow":                 schema_storage_apis_obotobotai_v1_Workflow(ref),
+		"github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.WorkflowCallback":         schema_storage_apis_obotobotai_v1_WorkflowCallback(ref),
+		"github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.WorkflowDryRun":           schema_storage_apis_obotobotai_v1_WorkflowDryRun(ref),
 		"github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.WorkflowExecution":        schema_storage_apis_obotobotai_v1_WorkflowExecution(ref),
 		"github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.WorkflowExecutionList":    schema_storage_apis_obotobotai_v1_WorkflowExecutionList(ref),
 		"github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.WorkflowExecutionSpec":    schema_storage_apis_obotobotai_v1_WorkflowExecutionSpec(ref),
 		"github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.WorkflowExecutionStatus":  schema_storage_apis_obotobotai_v1_WorkflowExecutionStatus(ref),
 		"github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.WorkflowList":             schema_storage_apis_obotobotai_v1_WorkflowList(ref),
+		"github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.WorkflowOnErrorStatus":    schema_storage_apis_obotobotai_v1_WorkflowOnErrorStatus(ref),
+		"github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.WorkflowRevision":         schema_storage_apis_obotobotai_v1_WorkflowRevision(ref),
+		"github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.WorkflowRevisionList":     schema_storage_apis_obotobotai_v1_WorkflowRevisionList(ref),
+		"github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.WorkflowRevisionSpec":     schema_storage_apis_obotobotai_v1_WorkflowRevisionSpec(ref),
+		"github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.WorkflowRevisionStatus":   schema_storage_apis_obotobotai_v1_WorkflowRevisionStatus(ref),
 		"github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.WorkflowSpec":             schema_storage_apis_obotobotai_v1_WorkflowSpec(ref),
 		"github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.WorkflowStatus":           schema_storage_apis_obotobotai_v1_WorkflowStatus(ref),
 		"github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.WorkflowStep":             schema_storage_apis_obotobotai_v1_WorkflowStep(ref),
+		"github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.WorkflowStepApproval":     schema_storage_apis_obotobotai_v1_WorkflowStepApproval(ref),
+		"github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.WorkflowStepAttempt":      schema_storage_apis_obotobotai_v1_WorkflowStepAttempt(ref),
 		"github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.WorkflowStepList":         schema_storage_apis_obotobotai_v1_WorkflowStepList(ref),
 		"github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.WorkflowStepSpec":         schema_storage_apis_obotobotai_v1_WorkflowStepSpec(ref),
 		"github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.WorkflowStepStatus":       schema_storage_apis_obotobotai_v1_WorkflowStepStatus(ref),
+		"github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.WorkflowStepSwitch":       schema_storage_apis_obotobotai_v1_WorkflowStepSwitch(ref),
+		"github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.WorkflowStepWait":         schema_storage_apis_obotobotai_v1_WorkflowStepWait(ref),
 		"github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.Workspace":                schema_storage_apis_obotobotai_v1_Workspace(ref),
 		"github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.WorkspaceList":            schema_storage_apis_obotobotai_v1_WorkspaceList(ref),
 		"github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.WorkspaceSpec":            schema_storage_apis_obotobotai_v1_WorkspaceSpec(ref),
@@ -1154,6 +1169,19 @@ func schema_obot_platform_obot_apiclient_types_CronJob(ref common.ReferenceCallb
 							Ref: ref("github.com/obot-platform/obot/apiclient/types.Time"),
 						},
 					},
+					"nextRuns": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/obot-platform/obot/apiclient/types.Time"),
+									},
+								},
+							},
+						},
+					},
 				},
 				Required: []string{"Metadata", "CronJobManifest"},
 			},
@@ -1226,6 +1254,36 @@ func schema_obot_platform_obot_apiclient_types_CronJobManifest(ref common.Refere
 							Ref: ref("github.com/obot-platform/obot/apiclient/types.Schedule"),
 						},
 					},
+					"timezone": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"concurrencyPolicy": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"startingDeadlineSeconds": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int64",
+						},
+					},
+					"catchUp": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"suspend": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"boolean"},
+							Format: "",
+						},
+					},
 				},
 			},
 		},
@@ -4231,6 +4289,36 @@ func schema_obot_platform_obot_apiclient_types_Webhook(ref common.ReferenceCallb
 	}
 }
 
+func schema_obot_platform_obot_apiclient_types_WebhookIdempotency(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"header": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"jsonPath": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"window": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
 func schema_obot_platform_obot_apiclient_types_WebhookList(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -4321,10 +4409,82 @@ func schema_obot_platform_obot_apiclient_types_WebhookManifest(ref common.Refere
 							Format:  "",
 						},
 					},
+					"signatureScheme": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"signature": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/obot-platform/obot/apiclient/types.WebhookSignature"),
+						},
+					},
+					"idempotency": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/obot-platform/obot/apiclient/types.WebhookIdempotency"),
+						},
+					},
 				},
 				Required: []string{"name", "description", "alias", "workflow", "headers", "secret", "validationHeader"},
 			},
 		},
+		Dependencies: []string{
+			"github.com/obot-platform/obot/apiclient/types.WebhookIdempotency", "github.com/obot-platform/obot/apiclient/types.WebhookSignature"},
+	}
+}
+
+func schema_obot_platform_obot_apiclient_types_WebhookSignature(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"header": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"algorithm": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"encoding": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"prefix": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"timestampHeader": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"payload": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"tolerance": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+				},
+			},
+		},
 	}
 }
 
@@ -5461,6 +5621,39 @@ func schema_storage_apis_obotobotai_v1_CronJobList(ref common.ReferenceCallback)
 	}
 }
 
+func schema_storage_apis_obotobotai_v1_CronJobSkippedRun(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"scheduledTime": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"workflowExecutionName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "WorkflowExecutionName is the execution that was cancelled to make room for a newer run, if any.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"reason": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
 func schema_storage_apis_obotobotai_v1_CronJobSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -5496,6 +5689,36 @@ func schema_storage_apis_obotobotai_v1_CronJobSpec(ref common.ReferenceCallback)
 							Ref: ref("github.com/obot-platform/obot/apiclient/types.Schedule"),
 						},
 					},
+					"timezone": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"concurrencyPolicy": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"startingDeadlineSeconds": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int64",
+						},
+					},
+					"catchUp": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"suspend": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"boolean"},
+							Format: "",
+						},
+					},
 					"threadName": {
 						SchemaProps: spec.SchemaProps{
 							Type:   []string{"string"},
@@ -5526,11 +5749,45 @@ func schema_storage_apis_obotobotai_v1_CronJobStatus(ref common.ReferenceCallbac
 							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
 						},
 					},
+					"lastScheduledTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "LastScheduledTime is the last scheduled time that was handled, whether it started a run or not.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"skippedRuns": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SkippedRuns are the most recent scheduled times that didn't start a run and the runs that were replaced by a newer one, oldest first.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.CronJobSkippedRun"),
+									},
+								},
+							},
+						},
+					},
+					"nextRuns": {
+						SchemaProps: spec.SchemaProps{
+							Description: "NextRuns are the next scheduled times of the cron job, empty while it is suspended.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+									},
+								},
+							},
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+			"github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.CronJobSkippedRun", "k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
 	}
 }
 
@@ -7301,6 +7558,13 @@ func schema_storage_apis_obotobotai_v1_RunSpec(ref common.ReferenceCallback) com
 							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.Duration"),
 						},
 					},
+					"toolCall": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ToolCall is set when the run calls a tool directly instead of chatting with an LLM.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
 				},
 				Required: []string{"input"},
 			},
@@ -7511,12 +7775,19 @@ func schema_storage_apis_obotobotai_v1_RunStatus(ref common.ReferenceCallback) c
 							Ref: ref("github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.TaskResult"),
 						},
 					},
+					"usage": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Usage is the number of tokens used by the LLM calls of the run so far.",
+							Default:     map[string]interface{}{},
+							Ref:         ref("github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.TokenUsage"),
+						},
+					},
 				},
 				Required: []string{"output"},
 			},
 		},
 		Dependencies: []string{
-			"github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.SubCall", "github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.TaskResult", "k8s.io/apimachinery/pkg/apis/meta/v1.Condition", "k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+			"github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.SubCall", "github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.TaskResult", "github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.TokenUsage", "k8s.io/apimachinery/pkg/apis/meta/v1.Condition", "k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
 	}
 }
 
@@ -7886,6 +8157,36 @@ func schema_storage_apis_obotobotai_v1_ThreadStatus(ref common.ReferenceCallback
 	}
 }
 
+func schema_storage_apis_obotobotai_v1_TokenUsage(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"promptTokens": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int64",
+						},
+					},
+					"completionTokens": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int64",
+						},
+					},
+					"totalTokens": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int64",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
 func schema_storage_apis_obotobotai_v1_Tool(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -8449,6 +8750,22 @@ func schema_storage_apis_obotobotai_v1_WebhookSpec(ref common.ReferenceCallback)
 							Format:  "",
 						},
 					},
+					"signatureScheme": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"signature": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/obot-platform/obot/apiclient/types.WebhookSignature"),
+						},
+					},
+					"idempotency": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/obot-platform/obot/apiclient/types.WebhookIdempotency"),
+						},
+					},
 					"tokenHash": {
 						SchemaProps: spec.SchemaProps{
 							Type:   []string{"string"},
@@ -8466,6 +8783,8 @@ func schema_storage_apis_obotobotai_v1_WebhookSpec(ref common.ReferenceCallback)
 				Required: []string{"name", "description", "alias", "workflow", "headers", "secret", "validationHeader", "ThreadName"},
 			},
 		},
+		Dependencies: []string{
+			"github.com/obot-platform/obot/apiclient/types.WebhookIdempotency", "github.com/obot-platform/obot/apiclient/types.WebhookSignature"},
 	}
 }
 
@@ -8546,20 +8865,89 @@ func schema_storage_apis_obotobotai_v1_Workflow(ref common.ReferenceCallback) co
 	}
 }
 
-func schema_storage_apis_obotobotai_v1_WorkflowExecution(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_storage_apis_obotobotai_v1_WorkflowCallback(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
 				Type: []string{"object"},
 				Properties: map[string]spec.Schema{
-					"kind": {
+					"payload": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Description: "Payload is the body of the callback, which becomes the output of the step.",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"apiVersion": {
+					"receivedAt": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
+func schema_storage_apis_obotobotai_v1_WorkflowDryRun(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"responses": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Responses are the responses of the steps by step ID. The Nth response is used for the Nth iteration of a loop or the Nth continuation of a step, and the last response once they run out.",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type: []string{"array"},
+										Items: &spec.SchemaOrArray{
+											Schema: &spec.Schema{
+												SchemaProps: spec.SchemaProps{
+													Default: "",
+													Type:    []string{"string"},
+													Format:  "",
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+					"callTools": {
+						SchemaProps: spec.SchemaProps{
+							Description: "CallTools calls the tools of tool steps instead of using the responses.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func schema_storage_apis_obotobotai_v1_WorkflowExecution(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
 						SchemaProps: spec.SchemaProps{
 							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
 							Type:        []string{"string"},
@@ -8725,9 +9113,44 @@ func schema_storage_apis_obotobotai_v1_WorkflowExecutionSpec(ref common.Referenc
 							Format: "",
 						},
 					},
+					"cancel": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Cancel stops the execution, its in-flight runs and its subflows.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"cancelledBy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "CancelledBy is the user that cancelled the execution.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"dryRun": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DryRun replaces the model responses of the steps with fixtures. Subflows inherit it from the parent execution.",
+							Ref:         ref("github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.WorkflowDryRun"),
+						},
+					},
+					"budget": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Budget overrides the limits of the budget of the workflow for this execution.",
+							Ref:         ref("github.com/obot-platform/obot/apiclient/types.WorkflowBudget"),
+						},
+					},
+					"webhookDeliveryKey": {
+						SchemaProps: spec.SchemaProps{
+							Description: "WebhookDeliveryKey is the hash of the idempotency key of the webhook delivery that started this execution.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 				},
 			},
 		},
+		Dependencies: []string{
+			"github.com/obot-platform/obot/apiclient/types.WorkflowBudget", "github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.WorkflowDryRun"},
 	}
 }
 
@@ -8777,11 +9200,75 @@ func schema_storage_apis_obotobotai_v1_WorkflowExecutionStatus(ref common.Refere
 							Format: "int64",
 						},
 					},
+					"structuredOutput": {
+						SchemaProps: spec.SchemaProps{
+							Description: "StructuredOutput is the output parsed as JSON, set when the workflow has an output schema.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"workflowRevision": {
+						SchemaProps: spec.SchemaProps{
+							Description: "WorkflowRevision is the revision of the workflow that WorkflowManifest was loaded from.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"onError": {
+						SchemaProps: spec.SchemaProps{
+							Description: "OnError is the state of the onError steps of the workflow, set once the execution failed.",
+							Ref:         ref("github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.WorkflowOnErrorStatus"),
+						},
+					},
+					"callbackToken": {
+						SchemaProps: spec.SchemaProps{
+							Description: "CallbackToken authenticates the callbacks that the waitForEvent steps of the execution wait for.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"callbacks": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Callbacks are the callbacks received for the waitForEvent steps of the execution by the ID of the step instance, which includes the loop iteration, switch case or onError suffix of the step.",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.WorkflowCallback"),
+									},
+								},
+							},
+						},
+					},
+					"usage": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Usage is the number of tokens used by the runs of the execution and its subflows.",
+							Default:     map[string]interface{}{},
+							Ref:         ref("github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.TokenUsage"),
+						},
+					},
+					"runUsage": {
+						SchemaProps: spec.SchemaProps{
+							Description: "RunUsage is the usage of every run and subflow of the execution by UID. Reruns delete runs and subflows, and recreate subflows under the same name, so their usage is kept here and never removed, which keeps Usage from dropping.",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.TokenUsage"),
+									},
+								},
+							},
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"github.com/obot-platform/obot/apiclient/types.WorkflowManifest", "k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+			"github.com/obot-platform/obot/apiclient/types.WorkflowManifest", "github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.TokenUsage", "github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.WorkflowCallback", "github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.WorkflowOnErrorStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
 	}
 }
 
@@ -8833,6 +9320,175 @@ func schema_storage_apis_obotobotai_v1_WorkflowList(ref common.ReferenceCallback
 	}
 }
 
+func schema_storage_apis_obotobotai_v1_WorkflowOnErrorStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"error": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Error is the error that the onError steps handle.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"handled": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Handled is set once the onError steps finished.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"recovered": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Recovered is set if the onError steps finished and marked the failure as recovered.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func schema_storage_apis_obotobotai_v1_WorkflowRevision(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"),
+						},
+					},
+					"spec": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.WorkflowRevisionSpec"),
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.WorkflowRevisionStatus"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.WorkflowRevisionSpec", "github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.WorkflowRevisionStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
+	}
+}
+
+func schema_storage_apis_obotobotai_v1_WorkflowRevisionList(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"),
+						},
+					},
+					"items": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.Tool"),
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"items"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.Tool", "k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"},
+	}
+}
+
+func schema_storage_apis_obotobotai_v1_WorkflowRevisionSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"workflowName": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"revision": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int64",
+						},
+					},
+					"manifest": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("github.com/obot-platform/obot/apiclient/types.WorkflowManifest"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/obot-platform/obot/apiclient/types.WorkflowManifest"},
+	}
+}
+
+func schema_storage_apis_obotobotai_v1_WorkflowRevisionStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+			},
+		},
+	}
+}
+
 func schema_storage_apis_obotobotai_v1_WorkflowSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -8951,6 +9607,20 @@ func schema_storage_apis_obotobotai_v1_WorkflowStatus(ref common.ReferenceCallba
 							Format: "int64",
 						},
 					},
+					"revision": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Revision is the number of the latest revision of the manifest.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"revisionGeneration": {
+						SchemaProps: spec.SchemaProps{
+							Description: "RevisionGeneration is the generation of the workflow that the latest revision was checked against.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
 				},
 			},
 		},
@@ -9005,6 +9675,81 @@ func schema_storage_apis_obotobotai_v1_WorkflowStep(ref common.ReferenceCallback
 	}
 }
 
+func schema_storage_apis_obotobotai_v1_WorkflowStepApproval(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"requestedAt": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"decision": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"decidedBy": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"decidedAt": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"comment": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
+func schema_storage_apis_obotobotai_v1_WorkflowStepAttempt(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"runName": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"error": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"endTime": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
 func schema_storage_apis_obotobotai_v1_WorkflowStepList(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -9083,6 +9828,20 @@ func schema_storage_apis_obotobotai_v1_WorkflowStepSpec(ref common.ReferenceCall
 							Format: "int64",
 						},
 					},
+					"item": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Item is the forEach item this step is run for, if any.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"error": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Error is the error handled by this step, set for the steps of an onError block.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 				},
 			},
 		},
@@ -9154,11 +9913,117 @@ func schema_storage_apis_obotobotai_v1_WorkflowStepStatus(ref common.ReferenceCa
 							Format: "",
 						},
 					},
+					"attempts": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Attempts records every finished attempt of this step, including the ones that were retried.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.WorkflowStepAttempt"),
+									},
+								},
+							},
+						},
+					},
+					"approval": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Approval is the state of the approval requested by an approval step.",
+							Ref:         ref("github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.WorkflowStepApproval"),
+						},
+					},
+					"outputSchemaRunNames": {
+						SchemaProps: spec.SchemaProps{
+							Description: "OutputSchemaRunNames are the runs that asked the model to correct an output that didn't match the output schema.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"structuredOutput": {
+						SchemaProps: spec.SchemaProps{
+							Description: "StructuredOutput is the output parsed as JSON, set when the step has an output schema.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"switch": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Switch is the case chosen by a switch step.",
+							Ref:         ref("github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.WorkflowStepSwitch"),
+						},
+					},
+					"onError": {
+						SchemaProps: spec.SchemaProps{
+							Description: "OnError is the state of the onError steps of the step, set once the step failed.",
+							Ref:         ref("github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.WorkflowOnErrorStatus"),
+						},
+					},
+					"wait": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Wait is the time a wait step resumes at.",
+							Ref:         ref("github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.WorkflowStepWait"),
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.SubCall"},
+			"github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.SubCall", "github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.WorkflowOnErrorStatus", "github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.WorkflowStepApproval", "github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.WorkflowStepAttempt", "github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.WorkflowStepSwitch", "github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1.WorkflowStepWait"},
+	}
+}
+
+func schema_storage_apis_obotobotai_v1_WorkflowStepSwitch(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"case": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Case is the name of the chosen case, or \"default\".",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"rationale": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func schema_storage_apis_obotobotai_v1_WorkflowStepWait(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"until": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Until is when the step stops waiting, computed once when the step starts so that it survives restarts.",
+							Default:     map[string]interface{}{},
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
 	}
 }
 
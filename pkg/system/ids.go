@@ -9,6 +9,7 @@ const (
 	WorkflowPrefix          = "w1"
 	WorkflowExecutionPrefix = "we1"
 	WorkflowStepPrefix      = "ws1"
+	WorkflowRevisionPrefix  = "wr1"
 	WorkspacePrefix         = "wksp1"
 	WebhookPrefix           = "wh1"
 	CronJobPrefix           = "cj1"
@@ -0,0 +1,323 @@
+package webhooksignature
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"mime"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/obot-platform/obot/apiclient/types"
+)
+
+const (
+	SchemeGitHub  = "github"
+	SchemeStripe  = "stripe"
+	SchemeSlack   = "slack"
+	SchemeShopify = "shopify"
+	SchemeTwilio  = "twilio"
+	SchemeCustom  = "custom"
+
+	// DefaultTolerance is how far the timestamp of a signed request can be from the time it is received.
+	DefaultTolerance = 5 * time.Minute
+)
+
+var schemes = []string{SchemeGitHub, SchemeStripe, SchemeSlack, SchemeShopify, SchemeTwilio, SchemeCustom}
+
+// Request is the part of an incoming request that a signature covers.
+type Request struct {
+	Header http.Header
+	// URL is the full URL that the sender called, which Twilio signs.
+	URL  string
+	Body []byte
+}
+
+// Validate checks the signature scheme of a webhook and its configuration.
+func Validate(scheme string, config *types.WebhookSignature) error {
+	if !slices.Contains(schemes, scheme) {
+		return fmt.Errorf("invalid signature scheme %q, must be one of %s", scheme, strings.Join(schemes, ", "))
+	}
+	if config == nil {
+		if scheme == SchemeCustom {
+			return fmt.Errorf("signature scheme %s requires a signature configuration", SchemeCustom)
+		}
+		return nil
+	}
+
+	if _, err := tolerance(config); err != nil {
+		return err
+	}
+	if scheme != SchemeCustom {
+		return nil
+	}
+
+	if config.Header == "" {
+		return fmt.Errorf("signature header is required")
+	}
+	if _, err := newHash(config.Algorithm); err != nil {
+		return err
+	}
+	if _, err := decoder(config.Encoding); err != nil {
+		return err
+	}
+	if strings.Contains(config.Payload, "{timestamp}") && config.TimestampHeader == "" {
+		return fmt.Errorf("signature payload uses {timestamp} but no timestamp header is set")
+	}
+	return nil
+}
+
+// Verify checks that the request is signed with the secret according to the signature scheme. Schemes that sign a
+// timestamp reject requests whose timestamp is further from now than the tolerance.
+func Verify(scheme string, config *types.WebhookSignature, secret string, req Request, now time.Time) error {
+	maxSkew, err := tolerance(config)
+	if err != nil {
+		return err
+	}
+
+	switch scheme {
+	case SchemeGitHub:
+		return verifyGitHub(secret, req)
+	case SchemeStripe:
+		return verifyStripe(secret, req, now, maxSkew)
+	case SchemeSlack:
+		return verifySlack(secret, req, now, maxSkew)
+	case SchemeShopify:
+		return verifyShopify(secret, req)
+	case SchemeTwilio:
+		return verifyTwilio(secret, req)
+	case SchemeCustom:
+		if config == nil {
+			return fmt.Errorf("signature scheme %s requires a signature configuration", SchemeCustom)
+		}
+		return verifyCustom(*config, secret, req, now, maxSkew)
+	default:
+		return fmt.Errorf("invalid signature scheme %q", scheme)
+	}
+}
+
+// verifyGitHub checks the sha256=<hex> HMAC-SHA256 of the body in X-Hub-Signature-256.
+func verifyGitHub(secret string, req Request) error {
+	expected := sign(sha256.New, secret, req.Body)
+	for _, v := range req.Header.Values("X-Hub-Signature-256") {
+		if sig, ok := strings.CutPrefix(strings.TrimSpace(v), "sha256="); ok && matchHex(expected, sig) {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid signature")
+}
+
+// verifyStripe checks the t=<timestamp>,v1=<hex> header of Stripe, which signs <timestamp>.<body> with
+// HMAC-SHA256. A secret that is being rolled sends more than one v1 signature.
+func verifyStripe(secret string, req Request, now time.Time, maxSkew time.Duration) error {
+	var (
+		timestamp  string
+		signatures []string
+	)
+	for _, part := range strings.Split(req.Header.Get("Stripe-Signature"), ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch k {
+		case "t":
+			timestamp = v
+		case "v1":
+			signatures = append(signatures, v)
+		}
+	}
+
+	if err := checkTimestamp(timestamp, now, maxSkew); err != nil {
+		return err
+	}
+
+	expected := sign(sha256.New, secret, []byte(timestamp+"."), req.Body)
+	for _, sig := range signatures {
+		if matchHex(expected, sig) {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid signature")
+}
+
+// verifySlack checks the v0=<hex> X-Slack-Signature header, which signs v0:<timestamp>:<body> with HMAC-SHA256
+// using the timestamp of X-Slack-Request-Timestamp.
+func verifySlack(secret string, req Request, now time.Time, maxSkew time.Duration) error {
+	timestamp := req.Header.Get("X-Slack-Request-Timestamp")
+	if err := checkTimestamp(timestamp, now, maxSkew); err != nil {
+		return err
+	}
+
+	expected := sign(sha256.New, secret, []byte("v0:"+timestamp+":"), req.Body)
+	if sig, ok := strings.CutPrefix(req.Header.Get("X-Slack-Signature"), "v0="); ok && matchHex(expected, sig) {
+		return nil
+	}
+	return fmt.Errorf("invalid signature")
+}
+
+// verifyShopify checks the base64 HMAC-SHA256 of the body in X-Shopify-Hmac-Sha256.
+func verifyShopify(secret string, req Request) error {
+	expected := sign(sha256.New, secret, req.Body)
+	if matchBase64(expected, req.Header.Get("X-Shopify-Hmac-Sha256")) {
+		return nil
+	}
+	return fmt.Errorf("invalid signature")
+}
+
+// verifyTwilio checks the base64 HMAC-SHA1 in X-Twilio-Signature. Twilio signs the full URL followed by the sorted
+// names and values of the parameters of a form post. JSON bodies aren't signed directly, Twilio adds their SHA256 to
+// the URL as bodySHA256 instead.
+func verifyTwilio(secret string, req Request) error {
+	u, err := url.Parse(req.URL)
+	if err != nil {
+		return fmt.Errorf("invalid request URL: %w", err)
+	}
+
+	payload := req.URL
+	if bodySHA := u.Query().Get("bodySHA256"); bodySHA != "" {
+		sum := sha256.Sum256(req.Body)
+		if !matchHex(sum[:], bodySHA) {
+			return fmt.Errorf("body doesn't match bodySHA256")
+		}
+	} else if mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type")); mediaType == "application/x-www-form-urlencoded" {
+		params, err := url.ParseQuery(string(req.Body))
+		if err != nil {
+			return fmt.Errorf("invalid form body: %w", err)
+		}
+		keys := make([]string, 0, len(params))
+		for k := range params {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		for _, k := range keys {
+			for _, v := range params[k] {
+				payload += k + v
+			}
+		}
+	}
+
+	expected := sign(sha1.New, secret, []byte(payload))
+	if matchBase64(expected, req.Header.Get("X-Twilio-Signature")) {
+		return nil
+	}
+	return fmt.Errorf("invalid signature")
+}
+
+// verifyCustom checks a signature as described by the configuration. The header can hold several comma separated
+// signatures, and only the ones that start with the prefix are considered if one is set.
+func verifyCustom(config types.WebhookSignature, secret string, req Request, now time.Time, maxSkew time.Duration) error {
+	hashFunc, err := newHash(config.Algorithm)
+	if err != nil {
+		return err
+	}
+	decode, err := decoder(config.Encoding)
+	if err != nil {
+		return err
+	}
+
+	var timestamp string
+	if config.TimestampHeader != "" {
+		timestamp = req.Header.Get(config.TimestampHeader)
+		if err := checkTimestamp(timestamp, now, maxSkew); err != nil {
+			return err
+		}
+	}
+
+	template := config.Payload
+	if template == "" {
+		template = "{body}"
+	}
+	payload := strings.NewReplacer("{timestamp}", timestamp, "{url}", req.URL, "{body}", string(req.Body)).Replace(template)
+	expected := sign(hashFunc, secret, []byte(payload))
+
+	for _, v := range req.Header.Values(config.Header) {
+		for _, sig := range strings.Split(v, ",") {
+			sig, ok := strings.CutPrefix(strings.TrimSpace(sig), config.Prefix)
+			if !ok {
+				continue
+			}
+			if b, err := decode(sig); err == nil && hmac.Equal(expected, b) {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("invalid signature")
+}
+
+func tolerance(config *types.WebhookSignature) (time.Duration, error) {
+	if config == nil || config.Tolerance == "" {
+		return DefaultTolerance, nil
+	}
+	d, err := time.ParseDuration(config.Tolerance)
+	if err != nil {
+		return 0, fmt.Errorf("invalid signature tolerance %q: %w", config.Tolerance, err)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("invalid signature tolerance %q: must be positive", config.Tolerance)
+	}
+	return d, nil
+}
+
+// checkTimestamp checks that the Unix timestamp of a request is within the tolerance of now, which keeps a captured
+// request from being replayed later.
+func checkTimestamp(timestamp string, now time.Time, maxSkew time.Duration) error {
+	if timestamp == "" {
+		return fmt.Errorf("missing signature timestamp")
+	}
+	seconds, err := strconv.ParseInt(strings.TrimSpace(timestamp), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid signature timestamp %q", timestamp)
+	}
+	if skew := now.Sub(time.Unix(seconds, 0)).Abs(); skew > maxSkew {
+		return fmt.Errorf("signature timestamp is %s off, more than the tolerance of %s", skew, maxSkew)
+	}
+	return nil
+}
+
+func newHash(algorithm string) (func() hash.Hash, error) {
+	switch algorithm {
+	case "sha1":
+		return sha1.New, nil
+	case "", "sha256":
+		return sha256.New, nil
+	case "sha512":
+		return sha512.New, nil
+	default:
+		return nil, fmt.Errorf("invalid signature algorithm %q, must be one of sha1, sha256, sha512", algorithm)
+	}
+}
+
+func decoder(encoding string) (func(string) ([]byte, error), error) {
+	switch encoding {
+	case "", "hex":
+		return hex.DecodeString, nil
+	case "base64":
+		return base64.StdEncoding.DecodeString, nil
+	default:
+		return nil, fmt.Errorf("invalid signature encoding %q, must be one of hex, base64", encoding)
+	}
+}
+
+func sign(newHash func() hash.Hash, secret string, parts ...[]byte) []byte {
+	h := hmac.New(newHash, []byte(secret))
+	for _, part := range parts {
+		_, _ = h.Write(part)
+	}
+	return h.Sum(nil)
+}
+
+func matchHex(expected []byte, sig string) bool {
+	b, err := hex.DecodeString(strings.TrimSpace(sig))
+	return err == nil && hmac.Equal(expected, b)
+}
+
+func matchBase64(expected []byte, sig string) bool {
+	b, err := base64.StdEncoding.DecodeString(strings.TrimSpace(sig))
+	return err == nil && hmac.Equal(expected, b)
+}
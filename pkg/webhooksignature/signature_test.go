@@ -0,0 +1,347 @@
+package webhooksignature
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/obot-platform/obot/apiclient/types"
+)
+
+const (
+	// slackBody is the body of the example request in the Slack documentation on verifying requests.
+	slackBody = "token=xyzz0WbapA4vBCDEFasx0q6G&team_id=T1DC2JH3J&team_domain=testteamnow&channel_id=G8PSS9T3V" +
+		"&channel_name=foobar&user_id=U2CERLKJA&user_name=roadrunner&command=%2Fwebhook-collect&text=" +
+		"&response_url=https%3A%2F%2Fhooks.slack.com%2Fcommands%2FT1DC2JH3J%2F397700885554%2F96rGlfmibIGlgcZRskXaIFfN" +
+		"&trigger_id=398738663015.47445629121.803a0bc887a14d10d2c447fce8b6703c"
+	slackSignature = "v0=a2114d57b48eac39b9ad189dd8316235a7b4a8d21a10bd27519666489c69b503"
+	slackTimestamp = 1531420618
+
+	// twilioBody and twilioURL are the example request of the Twilio documentation on webhook security.
+	twilioBody = "CallSid=CA1234567890ABCDE&Caller=%2B12349013030&Digits=1234&From=%2B12349013030&To=%2B18005551212"
+	twilioURL  = "https://mycompany.com/myapp.php?foo=1&bar=2"
+
+	stripeBody      = "{\n  \"id\": \"evt_test_webhook\",\n  \"object\": \"event\"\n}"
+	stripeSignature = "c2f890decbc5ede7c5060bb9a6d31e0626a4342bacb9aeae2adb1bcea72f9812"
+	stripeTimestamp = 1492774577
+)
+
+func TestVerify(t *testing.T) {
+	customConfig := &types.WebhookSignature{
+		Header:          "X-Signature",
+		Algorithm:       "sha512",
+		Prefix:          "sha512=",
+		Payload:         "{timestamp}.{url}.{body}",
+		TimestampHeader: "X-Timestamp",
+	}
+	customSignature := "sha512=f3b4cac8224dfd3724748877d2bf78fbc1b469e2b77f8b7d72607c24875ed110edf52e843eb74007210fce8204b1f0" +
+		"b666abc89464c9e3c9daf9024ba8a78e31"
+
+	tests := []struct {
+		name    string
+		scheme  string
+		config  *types.WebhookSignature
+		secret  string
+		header  http.Header
+		url     string
+		body    string
+		now     time.Time
+		wantErr bool
+	}{
+		{
+			// The example of the GitHub documentation on validating webhook deliveries.
+			name:   "github",
+			scheme: SchemeGitHub,
+			secret: "It's a Secret to Everybody",
+			header: http.Header{"X-Hub-Signature-256": {"sha256=757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17"}},
+			body:   "Hello, World!",
+		},
+		{
+			name:    "github with the wrong secret",
+			scheme:  SchemeGitHub,
+			secret:  "It's a Secret to Nobody",
+			header:  http.Header{"X-Hub-Signature-256": {"sha256=757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17"}},
+			body:    "Hello, World!",
+			wantErr: true,
+		},
+		{
+			name:    "github without a signature",
+			scheme:  SchemeGitHub,
+			secret:  "It's a Secret to Everybody",
+			body:    "Hello, World!",
+			wantErr: true,
+		},
+		{
+			name:   "stripe",
+			scheme: SchemeStripe,
+			secret: "whsec_test_secret",
+			header: http.Header{"Stripe-Signature": {"t=1492774577,v1=" + stripeSignature}},
+			body:   stripeBody,
+			now:    time.Unix(stripeTimestamp+60, 0),
+		},
+		{
+			name:   "stripe while rolling the secret",
+			scheme: SchemeStripe,
+			secret: "whsec_test_secret",
+			header: http.Header{"Stripe-Signature": {"t=1492774577,v1=" + stripeSignature[:60] + "0000,v1=" + stripeSignature}},
+			body:   stripeBody,
+			now:    time.Unix(stripeTimestamp, 0),
+		},
+		{
+			name:    "stripe with a tampered body",
+			scheme:  SchemeStripe,
+			secret:  "whsec_test_secret",
+			header:  http.Header{"Stripe-Signature": {"t=1492774577,v1=" + stripeSignature}},
+			body:    stripeBody + " ",
+			now:     time.Unix(stripeTimestamp, 0),
+			wantErr: true,
+		},
+		{
+			name:    "stripe older than the tolerance",
+			scheme:  SchemeStripe,
+			secret:  "whsec_test_secret",
+			header:  http.Header{"Stripe-Signature": {"t=1492774577,v1=" + stripeSignature}},
+			body:    stripeBody,
+			now:     time.Unix(stripeTimestamp, 0).Add(DefaultTolerance + time.Second),
+			wantErr: true,
+		},
+		{
+			name:   "stripe within a longer tolerance",
+			scheme: SchemeStripe,
+			config: &types.WebhookSignature{Tolerance: "15m"},
+			secret: "whsec_test_secret",
+			header: http.Header{"Stripe-Signature": {"t=1492774577,v1=" + stripeSignature}},
+			body:   stripeBody,
+			now:    time.Unix(stripeTimestamp, 0).Add(10 * time.Minute),
+		},
+		{
+			name:    "stripe without a timestamp",
+			scheme:  SchemeStripe,
+			secret:  "whsec_test_secret",
+			header:  http.Header{"Stripe-Signature": {"v1=" + stripeSignature}},
+			body:    stripeBody,
+			now:     time.Unix(stripeTimestamp, 0),
+			wantErr: true,
+		},
+		{
+			// The example of the Slack documentation on verifying requests.
+			name:   "slack",
+			scheme: SchemeSlack,
+			secret: "8f742231b10e8888abcd99yyyzzz85a5",
+			header: http.Header{
+				"X-Slack-Request-Timestamp": {"1531420618"},
+				"X-Slack-Signature":         {slackSignature},
+			},
+			body: slackBody,
+			now:  time.Unix(slackTimestamp+30, 0),
+		},
+		{
+			name:   "slack older than the tolerance",
+			scheme: SchemeSlack,
+			secret: "8f742231b10e8888abcd99yyyzzz85a5",
+			header: http.Header{
+				"X-Slack-Request-Timestamp": {"1531420618"},
+				"X-Slack-Signature":         {slackSignature},
+			},
+			body:    slackBody,
+			now:     time.Unix(slackTimestamp, 0).Add(6 * time.Minute),
+			wantErr: true,
+		},
+		{
+			name:   "slack from the future",
+			scheme: SchemeSlack,
+			secret: "8f742231b10e8888abcd99yyyzzz85a5",
+			header: http.Header{
+				"X-Slack-Request-Timestamp": {"1531420618"},
+				"X-Slack-Signature":         {slackSignature},
+			},
+			body:    slackBody,
+			now:     time.Unix(slackTimestamp, 0).Add(-6 * time.Minute),
+			wantErr: true,
+		},
+		{
+			name:   "slack with a replayed timestamp",
+			scheme: SchemeSlack,
+			secret: "8f742231b10e8888abcd99yyyzzz85a5",
+			header: http.Header{
+				"X-Slack-Request-Timestamp": {"1531420619"},
+				"X-Slack-Signature":         {slackSignature},
+			},
+			body:    slackBody,
+			now:     time.Unix(slackTimestamp, 0),
+			wantErr: true,
+		},
+		{
+			name:   "shopify",
+			scheme: SchemeShopify,
+			secret: "hush",
+			header: http.Header{"X-Shopify-Hmac-Sha256": {"D0UMXmhjwBRi4y66TmhJrDlhQABD7zI2fm3p6/QLMo8="}},
+			body:   `{"id":820982911946154508,"email":"jon@example.com"}`,
+		},
+		{
+			name:    "shopify with a tampered body",
+			scheme:  SchemeShopify,
+			secret:  "hush",
+			header:  http.Header{"X-Shopify-Hmac-Sha256": {"D0UMXmhjwBRi4y66TmhJrDlhQABD7zI2fm3p6/QLMo8="}},
+			body:    `{"id":820982911946154509,"email":"jon@example.com"}`,
+			wantErr: true,
+		},
+		{
+			// The example of the Twilio documentation on webhook security.
+			name:   "twilio",
+			scheme: SchemeTwilio,
+			secret: "12345",
+			header: http.Header{
+				"Content-Type":       {"application/x-www-form-urlencoded"},
+				"X-Twilio-Signature": {"0/KCTR6DLpKmkAf8muzZqo1nDgQ="},
+			},
+			url:  twilioURL,
+			body: twilioBody,
+		},
+		{
+			name:   "twilio with a tampered parameter",
+			scheme: SchemeTwilio,
+			secret: "12345",
+			header: http.Header{
+				"Content-Type":       {"application/x-www-form-urlencoded"},
+				"X-Twilio-Signature": {"0/KCTR6DLpKmkAf8muzZqo1nDgQ="},
+			},
+			url:     twilioURL,
+			body:    twilioBody + "&Extra=1",
+			wantErr: true,
+		},
+		{
+			name:   "twilio with a JSON body",
+			scheme: SchemeTwilio,
+			secret: "12345",
+			header: http.Header{
+				"Content-Type":       {"application/json"},
+				"X-Twilio-Signature": {"C8sJ7v4QWOODDruoAbJ+vnu8RQ4="},
+			},
+			url:  "https://mycompany.com/myapp.php?bodySHA256=e852ec28d46c49841e1e6687b51c3dbf2b3da45f0b5f73184b3220daaa45ab3b",
+			body: `{"CallSid":"CA1234567890ABCDE"}`,
+		},
+		{
+			name:   "twilio with a JSON body that doesn't match bodySHA256",
+			scheme: SchemeTwilio,
+			secret: "12345",
+			header: http.Header{
+				"Content-Type":       {"application/json"},
+				"X-Twilio-Signature": {"C8sJ7v4QWOODDruoAbJ+vnu8RQ4="},
+			},
+			url:     "https://mycompany.com/myapp.php?bodySHA256=e852ec28d46c49841e1e6687b51c3dbf2b3da45f0b5f73184b3220daaa45ab3b",
+			body:    `{"CallSid":"CA1234567890ABCDF"}`,
+			wantErr: true,
+		},
+		{
+			name:   "custom",
+			scheme: SchemeCustom,
+			config: customConfig,
+			secret: "custom",
+			header: http.Header{
+				"X-Timestamp": {"1700000000"},
+				"X-Signature": {"sha1=0000, " + customSignature},
+			},
+			url:  "https://example.com/hook",
+			body: `{"a":1}`,
+			now:  time.Unix(1700000000, 0),
+		},
+		{
+			name:   "custom older than the tolerance",
+			scheme: SchemeCustom,
+			config: customConfig,
+			secret: "custom",
+			header: http.Header{
+				"X-Timestamp": {"1700000000"},
+				"X-Signature": {customSignature},
+			},
+			url:     "https://example.com/hook",
+			body:    `{"a":1}`,
+			now:     time.Unix(1700000000, 0).Add(time.Hour),
+			wantErr: true,
+		},
+		{
+			name:    "custom without a configuration",
+			scheme:  SchemeCustom,
+			secret:  "custom",
+			header:  http.Header{"X-Signature": {customSignature}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := Request{
+				Header: tt.header,
+				URL:    tt.url,
+				Body:   []byte(tt.body),
+			}
+			if req.Header == nil {
+				req.Header = http.Header{}
+			}
+
+			err := Verify(tt.scheme, tt.config, tt.secret, req, tt.now)
+			if tt.wantErr && err == nil {
+				t.Fatal("Verify() = nil, want error")
+			} else if !tt.wantErr && err != nil {
+				t.Fatalf("Verify() returned error: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		scheme  string
+		config  *types.WebhookSignature
+		wantErr bool
+	}{
+		{name: "provider without a configuration", scheme: SchemeStripe},
+		{name: "provider with a tolerance", scheme: SchemeSlack, config: &types.WebhookSignature{Tolerance: "1m"}},
+		{name: "unknown scheme", scheme: "paypal", wantErr: true},
+		{name: "negative tolerance", scheme: SchemeStripe, config: &types.WebhookSignature{Tolerance: "-1m"}, wantErr: true},
+		{name: "custom without a configuration", scheme: SchemeCustom, wantErr: true},
+		{name: "custom without a header", scheme: SchemeCustom, config: &types.WebhookSignature{}, wantErr: true},
+		{
+			name:    "custom with an unknown algorithm",
+			scheme:  SchemeCustom,
+			config:  &types.WebhookSignature{Header: "X-Signature", Algorithm: "md5"},
+			wantErr: true,
+		},
+		{
+			name:    "custom with an unknown encoding",
+			scheme:  SchemeCustom,
+			config:  &types.WebhookSignature{Header: "X-Signature", Encoding: "base32"},
+			wantErr: true,
+		},
+		{
+			name:    "custom timestamp without a timestamp header",
+			scheme:  SchemeCustom,
+			config:  &types.WebhookSignature{Header: "X-Signature", Payload: "{timestamp}.{body}"},
+			wantErr: true,
+		},
+		{
+			name:   "custom",
+			scheme: SchemeCustom,
+			config: &types.WebhookSignature{
+				Header:          "X-Signature",
+				Algorithm:       "sha1",
+				Encoding:        "base64",
+				Payload:         "{timestamp}.{body}",
+				TimestampHeader: "X-Timestamp",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.scheme, tt.config)
+			if tt.wantErr && err == nil {
+				t.Fatal("Validate() = nil, want error")
+			} else if !tt.wantErr && err != nil {
+				t.Fatalf("Validate() returned error: %v", err)
+			}
+		})
+	}
+}
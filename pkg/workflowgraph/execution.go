@@ -0,0 +1,321 @@
+package workflowgraph
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/obot-platform/obot/apiclient/types"
+	v1 "github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1"
+)
+
+// Execution is a workflow execution with the steps of its current generation, the executions of its subflows and
+// the output of the runs that evaluated a condition.
+type Execution struct {
+	Execution v1.WorkflowExecution
+	Steps     []v1.WorkflowStep
+	Subflows  []Execution
+	Outputs   map[string]string
+}
+
+// FromExecution returns the graph of the steps that ran in a workflow execution. Steps are grouped by the step of the
+// manifest that created them, so each iteration of a loop and the chosen branch of a condition show up as their own
+// nodes, and subflows are nested under the step that called them. If runLink is set, nodes link to the last run of
+// the step.
+func FromExecution(exec Execution, runLink func(runName string) string) *Graph {
+	g := newGraph()
+	g.addExecution(exec, "", false, runLink)
+	return g
+}
+
+// manifestParent is the step of the manifest that a nested step belongs to, either as part of its body or its
+// onError block.
+type manifestParent struct {
+	id      string
+	onError bool
+}
+
+type executionGraph struct {
+	*Graph
+	exec    Execution
+	cluster string
+	parents map[string]manifestParent
+}
+
+func (g *Graph) addExecution(exec Execution, parentCluster string, subflow bool, runLink func(string) string) string {
+	wfe := exec.Execution
+	e := executionGraph{
+		Graph:   g,
+		exec:    exec,
+		parents: map[string]manifestParent{},
+	}
+
+	if subflow {
+		e.cluster = g.id(wfe.Name)
+		g.addCluster(cluster{ID: e.cluster, Parent: parentCluster, Label: fmt.Sprintf("subflow %s", wfe.Spec.WorkflowName)})
+	}
+
+	start := g.addNode(node{
+		ID:      g.id(""),
+		Cluster: e.cluster,
+		Label:   fmt.Sprintf("%s\n%s\n%s", wfe.Spec.WorkflowName, wfe.Name, wfe.Status.State),
+		Shape:   shapeTerminal,
+		State:   wfe.Status.State,
+	})
+
+	// The manifest isn't loaded until the execution starts, and there are no steps to show before then.
+	manifest := wfe.Status.WorkflowManifest
+	if manifest == nil {
+		return start
+	}
+
+	collectParents(e.parents, "", false, manifest.Steps)
+	if manifest.OnError != nil {
+		collectParents(e.parents, "", true, manifest.OnError.Steps)
+	}
+
+	steps := slices.Clone(exec.Steps)
+	slices.SortStableFunc(steps, func(a, b v1.WorkflowStep) int {
+		return a.CreationTimestamp.Compare(b.CreationTimestamp.Time)
+	})
+
+	for _, step := range steps {
+		base, synthetic := splitStepID(step.Spec.Step.ID)
+
+		clusterID := e.containerCluster(base)
+		if synthetic {
+			clusterID = e.stepCluster(base)
+		}
+
+		var runName string
+		if step.Status.LastRunName != "" {
+			runName = step.Status.LastRunName
+		} else if len(step.Status.RunNames) > 0 {
+			runName = step.Status.RunNames[len(step.Status.RunNames)-1]
+		}
+
+		var link string
+		if runLink != nil && runName != "" {
+			link = runLink(runName)
+		}
+
+		g.addNode(node{
+			ID:      g.id(step.Name),
+			Cluster: clusterID,
+			Label:   e.stepLabel(step, synthetic, runName),
+			Shape:   stepShape(step.Spec.Step, synthetic),
+			State:   step.Status.State,
+			Link:    link,
+		})
+	}
+
+	for _, step := range steps {
+		e.addStepEdge(steps, step, start)
+	}
+
+	for _, subflow := range exec.Subflows {
+		caller := slices.IndexFunc(steps, func(step v1.WorkflowStep) bool {
+			return slices.Contains(step.Status.RunNames, subflow.Execution.Spec.ParentRunName)
+		})
+		subflowCluster := e.cluster
+		if caller >= 0 {
+			subflowCluster = e.nodeCluster(g.id(steps[caller].Name))
+		}
+		subflowStart := g.addExecution(subflow, subflowCluster, true, runLink)
+		if caller >= 0 {
+			g.addEdge(edge{From: g.id(steps[caller].Name), To: subflowStart, Label: "subflow", Dashed: true})
+		}
+	}
+
+	return start
+}
+
+func (e executionGraph) nodeCluster(id string) string {
+	for _, n := range e.nodes {
+		if n.ID == id {
+			return n.Cluster
+		}
+	}
+	return e.cluster
+}
+
+// addStepEdge connects the step to the step it ran after. The first steps of a body start after the same step as
+// the step that owns them, so they are connected to the owner instead, which keeps the body inside of the owner.
+func (e executionGraph) addStepEdge(steps []v1.WorkflowStep, step v1.WorkflowStep, start string) {
+	from := start
+	if slices.ContainsFunc(steps, func(other v1.WorkflowStep) bool { return other.Name == step.Spec.AfterWorkflowStepName }) {
+		from = e.id(step.Spec.AfterWorkflowStepName)
+	}
+
+	var (
+		label  string
+		dashed bool
+	)
+	if owner, onError, ok := e.owner(steps, step); ok {
+		from = e.id(owner.Name)
+		if onError {
+			label, dashed = "error", true
+		}
+	}
+
+	e.addEdge(edge{From: from, To: e.id(step.Name), Label: label, Dashed: dashed})
+}
+
+// owner returns the step that created the given step if the given step is one of the first steps of its body. The
+// first steps of a body start after the same step as their owner, which tells apart the owners of nested loops that
+// ran the same step of the manifest. The onError steps of a step have the ID of the failed step in their own.
+func (e executionGraph) owner(steps []v1.WorkflowStep, step v1.WorkflowStep) (v1.WorkflowStep, bool, bool) {
+	base, synthetic := splitStepID(step.Spec.Step.ID)
+
+	ownerID, onError := base, false
+	if !synthetic {
+		parent, ok := e.parents[base]
+		if !ok || parent.id == "" {
+			return v1.WorkflowStep{}, false, false
+		}
+		ownerID, onError = parent.id, parent.onError
+	}
+
+	_, failedStepID, _ := strings.Cut(step.Spec.Step.ID, "{onError=")
+	failedStepID = strings.TrimSuffix(failedStepID, "}")
+
+	for _, other := range steps {
+		if other.Name == step.Name || other.Spec.AfterWorkflowStepName != step.Spec.AfterWorkflowStepName {
+			continue
+		}
+		if otherBase, otherSynthetic := splitStepID(other.Spec.Step.ID); otherBase != ownerID || otherSynthetic {
+			continue
+		}
+		if onError && failedStepID != "" && other.Spec.Step.ID != failedStepID {
+			continue
+		}
+		return other, onError, true
+	}
+	return v1.WorkflowStep{}, false, false
+}
+
+// containerCluster returns the cluster for the nodes of the steps created for the given step of the manifest.
+func (e executionGraph) containerCluster(manifestID string) string {
+	parent, ok := e.parents[manifestID]
+	switch {
+	case !ok:
+		return e.cluster
+	case parent.onError:
+		return e.onErrorCluster(parent.id)
+	default:
+		return e.stepCluster(parent.id)
+	}
+}
+
+// stepCluster returns the cluster for the body of the given step of the manifest.
+func (e executionGraph) stepCluster(manifestID string) string {
+	clusterID := e.id(e.exec.Execution.Name + "/" + manifestID)
+	e.addCluster(cluster{ID: clusterID, Parent: e.containerCluster(manifestID), Label: manifestID})
+	return clusterID
+}
+
+// onErrorCluster returns the cluster for the onError block of the given step of the manifest, or of the workflow if
+// the ID is empty.
+func (e executionGraph) onErrorCluster(manifestID string) string {
+	clusterID := e.id(e.exec.Execution.Name + "/" + manifestID + "{onError}")
+	if manifestID == "" {
+		e.addCluster(cluster{ID: clusterID, Parent: e.cluster, Label: "onError"})
+	} else {
+		e.addCluster(cluster{ID: clusterID, Parent: e.containerCluster(manifestID), Label: "onError: " + manifestID})
+	}
+	return clusterID
+}
+
+func (e executionGraph) stepLabel(step v1.WorkflowStep, synthetic bool, runName string) string {
+	lines := []string{step.Spec.Step.ID, string(step.Status.State)}
+	if step.Status.Switch != nil {
+		lines = append(lines, "case: "+step.Status.Switch.Case)
+	}
+	if synthetic && strings.Contains(step.Spec.Step.ID, "{condition") && step.Status.LastRunName != "" {
+		if output, ok := e.exec.Outputs[step.Status.LastRunName]; ok {
+			lines = append(lines, "result: "+summary(output))
+		}
+	}
+	if step.Status.Wait != nil && step.Status.State == types.WorkflowStateWaiting {
+		lines = append(lines, "until: "+step.Status.Wait.Until.UTC().Format(time.RFC3339))
+	}
+	if step.Status.OnError != nil {
+		if step.Status.OnError.Recovered {
+			lines = append(lines, "recovered: "+summary(step.Status.OnError.Error))
+		} else {
+			lines = append(lines, "onError: "+summary(step.Status.OnError.Error))
+		}
+	} else if step.Status.Error != "" {
+		lines = append(lines, "error: "+summary(step.Status.Error))
+	}
+	if runName != "" {
+		lines = append(lines, "run: "+runName)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func stepShape(step types.Step, synthetic bool) shape {
+	switch {
+	case synthetic && (strings.Contains(step.ID, "{condition") || strings.Contains(step.ID, "{switch")):
+		return shapeDecision
+	case synthetic:
+		return shapeFork
+	case step.If != nil || step.Switch != nil:
+		return shapeDecision
+	case step.While != nil || step.ForEach != nil:
+		return shapeLoop
+	case step.Parallel != nil:
+		return shapeFork
+	case step.Approval != nil:
+		return shapeApproval
+	default:
+		return shapeStep
+	}
+}
+
+// splitStepID returns the ID of the step of the manifest that a step was created for and whether the step was
+// created by its owner to evaluate a condition, classify a switch or join parallel branches, rather than being part
+// of its body.
+func splitStepID(stepID string) (string, bool) {
+	base, suffix, ok := strings.Cut(stepID, "{")
+	if !ok {
+		return stepID, false
+	}
+	for _, part := range strings.FieldsFunc(suffix, func(r rune) bool { return r == '{' || r == '}' || r == ',' }) {
+		switch part {
+		case "condition", "switch", "join":
+			return base, true
+		}
+	}
+	return base, false
+}
+
+func collectParents(parents map[string]manifestParent, parentID string, onError bool, steps []types.Step) {
+	for _, step := range steps {
+		if parentID != "" || onError {
+			parents[step.ID] = manifestParent{id: parentID, onError: onError}
+		}
+		switch {
+		case step.If != nil:
+			collectParents(parents, step.ID, false, step.If.Steps)
+			collectParents(parents, step.ID, false, step.If.Else)
+		case step.While != nil:
+			collectParents(parents, step.ID, false, step.While.Steps)
+		case step.ForEach != nil:
+			collectParents(parents, step.ID, false, step.ForEach.Steps)
+		case step.Parallel != nil:
+			for _, branch := range step.Parallel.Branches {
+				collectParents(parents, step.ID, false, branch.Steps)
+			}
+		case step.Switch != nil:
+			for _, c := range step.Switch.Cases {
+				collectParents(parents, step.ID, false, c.Steps)
+			}
+			collectParents(parents, step.ID, false, step.Switch.Default)
+		}
+		if step.OnError != nil {
+			collectParents(parents, step.ID, true, step.OnError.Steps)
+		}
+	}
+}
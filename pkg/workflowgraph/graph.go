@@ -0,0 +1,273 @@
+package workflowgraph
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/obot-platform/obot/apiclient/types"
+)
+
+const (
+	FormatMermaid = "mermaid"
+	FormatDOT     = "dot"
+)
+
+type shape int
+
+const (
+	shapeStep shape = iota
+	shapeDecision
+	shapeLoop
+	shapeFork
+	shapeApproval
+	shapeTerminal
+)
+
+// Graph is a directed graph of the steps of a workflow or a workflow execution. Nodes and clusters are rendered in
+// the order they were added, so that the output is stable.
+type Graph struct {
+	nodes    []node
+	edges    []edge
+	clusters []cluster
+	ids      map[string]string
+	lastID   int
+}
+
+type node struct {
+	ID      string
+	Cluster string
+	Label   string
+	Shape   shape
+	State   types.WorkflowState
+	Link    string
+}
+
+type edge struct {
+	From   string
+	To     string
+	Label  string
+	Dashed bool
+}
+
+type cluster struct {
+	ID     string
+	Parent string
+	Label  string
+}
+
+func newGraph() *Graph {
+	return &Graph{
+		ids: map[string]string{},
+	}
+}
+
+// id returns the ID of the node or cluster for the given key, generating one that is valid in both Mermaid and DOT
+// the first time the key is seen. An empty key always generates a new ID.
+func (g *Graph) id(key string) string {
+	if id, ok := g.ids[key]; ok && key != "" {
+		return id
+	}
+	g.lastID++
+	id := fmt.Sprintf("n%d", g.lastID)
+	if key != "" {
+		g.ids[key] = id
+	}
+	return id
+}
+
+func (g *Graph) addNode(n node) string {
+	g.nodes = append(g.nodes, n)
+	return n.ID
+}
+
+func (g *Graph) addEdge(e edge) {
+	g.edges = append(g.edges, e)
+}
+
+func (g *Graph) addCluster(c cluster) {
+	if !slices.ContainsFunc(g.clusters, func(existing cluster) bool { return existing.ID == c.ID }) {
+		g.clusters = append(g.clusters, c)
+	}
+}
+
+// Render renders the graph in the given format.
+func (g *Graph) Render(format string) (string, error) {
+	switch format {
+	case "", FormatMermaid:
+		return g.mermaid(), nil
+	case FormatDOT:
+		return g.dot(), nil
+	default:
+		return "", fmt.Errorf("invalid format %q, must be %s or %s", format, FormatMermaid, FormatDOT)
+	}
+}
+
+func (g *Graph) mermaid() string {
+	var sb strings.Builder
+	sb.WriteString("flowchart TD\n")
+	g.writeMermaidCluster(&sb, "", 1)
+
+	for _, e := range g.edges {
+		arrow := "-->"
+		if e.Dashed {
+			arrow = "-.->"
+		}
+		if e.Label != "" {
+			fmt.Fprintf(&sb, "    %s %s|%s| %s\n", e.From, arrow, mermaidEscape(e.Label), e.To)
+		} else {
+			fmt.Fprintf(&sb, "    %s %s %s\n", e.From, arrow, e.To)
+		}
+	}
+
+	var states []types.WorkflowState
+	for _, n := range g.nodes {
+		if n.State != "" {
+			fmt.Fprintf(&sb, "    class %s %s\n", n.ID, n.State)
+			if !slices.Contains(states, n.State) {
+				states = append(states, n.State)
+			}
+		}
+		if n.Link != "" {
+			fmt.Fprintf(&sb, "    click %s %q\n", n.ID, n.Link)
+		}
+	}
+	for _, state := range states {
+		fmt.Fprintf(&sb, "    classDef %s fill:%s\n", state, stateColor(state))
+	}
+
+	return sb.String()
+}
+
+func (g *Graph) writeMermaidCluster(sb *strings.Builder, clusterID string, depth int) {
+	indent := strings.Repeat("    ", depth)
+	for _, n := range g.nodes {
+		if n.Cluster != clusterID {
+			continue
+		}
+		label := mermaidEscape(n.Label)
+		switch n.Shape {
+		case shapeDecision:
+			fmt.Fprintf(sb, "%s%s{\"%s\"}\n", indent, n.ID, label)
+		case shapeLoop:
+			fmt.Fprintf(sb, "%s%s[[\"%s\"]]\n", indent, n.ID, label)
+		case shapeFork:
+			fmt.Fprintf(sb, "%s%s[/\"%s\"\\]\n", indent, n.ID, label)
+		case shapeApproval:
+			fmt.Fprintf(sb, "%s%s{{\"%s\"}}\n", indent, n.ID, label)
+		case shapeTerminal:
+			fmt.Fprintf(sb, "%s%s([\"%s\"])\n", indent, n.ID, label)
+		default:
+			fmt.Fprintf(sb, "%s%s[\"%s\"]\n", indent, n.ID, label)
+		}
+	}
+
+	for _, child := range g.clusters {
+		if child.Parent != clusterID {
+			continue
+		}
+		fmt.Fprintf(sb, "%ssubgraph %s[\"%s\"]\n", indent, child.ID, mermaidEscape(child.Label))
+		g.writeMermaidCluster(sb, child.ID, depth+1)
+		fmt.Fprintf(sb, "%send\n", indent)
+	}
+}
+
+func (g *Graph) dot() string {
+	var sb strings.Builder
+	sb.WriteString("digraph workflow {\n")
+	sb.WriteString("    node [shape=box, style=\"rounded,filled\", fillcolor=\"#ffffff\"];\n")
+	g.writeDOTCluster(&sb, "", 1)
+
+	for _, e := range g.edges {
+		var attrs []string
+		if e.Label != "" {
+			attrs = append(attrs, fmt.Sprintf("label=%s", dotQuote(e.Label)))
+		}
+		if e.Dashed {
+			attrs = append(attrs, "style=dashed")
+		}
+		if len(attrs) > 0 {
+			fmt.Fprintf(&sb, "    %s -> %s [%s];\n", e.From, e.To, strings.Join(attrs, ", "))
+		} else {
+			fmt.Fprintf(&sb, "    %s -> %s;\n", e.From, e.To)
+		}
+	}
+
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+func (g *Graph) writeDOTCluster(sb *strings.Builder, clusterID string, depth int) {
+	indent := strings.Repeat("    ", depth)
+	for _, n := range g.nodes {
+		if n.Cluster != clusterID {
+			continue
+		}
+		attrs := []string{"label=" + dotQuote(n.Label)}
+		switch n.Shape {
+		case shapeDecision:
+			attrs = append(attrs, "shape=diamond")
+		case shapeLoop:
+			attrs = append(attrs, "shape=box3d")
+		case shapeFork:
+			attrs = append(attrs, "shape=trapezium")
+		case shapeApproval:
+			attrs = append(attrs, "shape=hexagon")
+		case shapeTerminal:
+			attrs = append(attrs, "shape=oval")
+		}
+		if n.State != "" {
+			attrs = append(attrs, "fillcolor="+dotQuote(stateColor(n.State)))
+		}
+		if n.Link != "" {
+			attrs = append(attrs, "URL="+dotQuote(n.Link))
+		}
+		fmt.Fprintf(sb, "%s%s [%s];\n", indent, n.ID, strings.Join(attrs, ", "))
+	}
+
+	for _, child := range g.clusters {
+		if child.Parent != clusterID {
+			continue
+		}
+		fmt.Fprintf(sb, "%ssubgraph cluster_%s {\n", indent, child.ID)
+		fmt.Fprintf(sb, "%s    label=%s;\n", indent, dotQuote(child.Label))
+		g.writeDOTCluster(sb, child.ID, depth+1)
+		fmt.Fprintf(sb, "%s}\n", indent)
+	}
+}
+
+func stateColor(state types.WorkflowState) string {
+	switch state {
+	case types.WorkflowStateComplete, types.WorkflowStateRecovered:
+		return "#d4edda"
+	case types.WorkflowStateError:
+		return "#f8d7da"
+	case types.WorkflowStateRunning, types.WorkflowStateSubCall:
+		return "#cce5ff"
+	case types.WorkflowStateBlocked, types.WorkflowStateWaitingApproval, types.WorkflowStateWaiting, types.WorkflowStateQueued, types.WorkflowStateCancelled:
+		return "#fff3cd"
+	default:
+		return "#e2e3e5"
+	}
+}
+
+func mermaidEscape(s string) string {
+	s = strings.ReplaceAll(s, `"`, "#quot;")
+	s = strings.ReplaceAll(s, "|", "#124;")
+	return strings.ReplaceAll(s, "\n", "<br/>")
+}
+
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + strings.ReplaceAll(s, "\n", `\n`) + `"`
+}
+
+// summary shortens text to a single line for use in a label.
+func summary(text string) string {
+	text, _, _ = strings.Cut(strings.TrimSpace(text), "\n")
+	if len(text) > 40 {
+		return text[:40] + "..."
+	}
+	return text
+}
@@ -0,0 +1,158 @@
+package workflowgraph
+
+import (
+	"fmt"
+
+	"github.com/obot-platform/obot/apiclient/types"
+)
+
+// tail is the end of a path through the graph that the next step connects to.
+type tail struct {
+	node   string
+	label  string
+	dashed bool
+}
+
+// FromManifest returns the graph of the definition of a workflow. Branches are labeled with the condition or case
+// that selects them, loops point back to the loop step and onError steps hang off the step they handle with a dashed
+// edge.
+func FromManifest(manifest types.WorkflowManifest) *Graph {
+	g := newGraph()
+
+	start := g.addNode(node{ID: g.id(""), Label: "start", Shape: shapeTerminal})
+	tails := g.addSteps("", []tail{{node: start}}, manifest.Steps)
+
+	if manifest.Output != "" {
+		output := g.addNode(node{ID: g.id(""), Label: "output\n" + summary(manifest.Output)})
+		g.connect(tails, output)
+		tails = []tail{{node: output}}
+	}
+
+	end := g.addNode(node{ID: g.id(""), Label: "end", Shape: shapeTerminal})
+	g.connect(tails, end)
+
+	if manifest.OnError != nil {
+		clusterID := g.id("")
+		g.addCluster(cluster{ID: clusterID, Label: "onError"})
+		failed := g.addNode(node{ID: g.id(""), Cluster: clusterID, Label: "error", Shape: shapeTerminal})
+		handlerTails := g.addSteps(clusterID, []tail{{node: failed}}, manifest.OnError.Steps)
+		if manifest.OnError.Recover {
+			g.connect(handlerTails, end)
+		}
+	}
+
+	return g
+}
+
+func (g *Graph) connect(tails []tail, to string) {
+	for _, t := range tails {
+		g.addEdge(edge{From: t.node, To: to, Label: t.label, Dashed: t.dashed})
+	}
+}
+
+func (g *Graph) addSteps(clusterID string, tails []tail, steps []types.Step) []tail {
+	for _, step := range steps {
+		tails = g.addStep(clusterID, tails, step)
+	}
+	return tails
+}
+
+func (g *Graph) addStep(parentCluster string, prev []tail, step types.Step) (tails []tail) {
+	id := g.id("")
+	g.connect(prev, id)
+
+	childCluster := func() string {
+		clusterID := g.id("")
+		g.addCluster(cluster{ID: clusterID, Parent: parentCluster, Label: step.ID})
+		return clusterID
+	}
+
+	switch {
+	case step.If != nil:
+		g.addNode(node{ID: id, Cluster: parentCluster, Label: stepLabel(step, "if", step.If.Condition), Shape: shapeDecision})
+		clusterID := childCluster()
+		tails = append(g.addSteps(clusterID, []tail{{node: id, label: "true"}}, step.If.Steps),
+			g.addSteps(clusterID, []tail{{node: id, label: "false"}}, step.If.Else)...)
+	case step.Switch != nil:
+		g.addNode(node{ID: id, Cluster: parentCluster, Label: stepLabel(step, "switch", step.Switch.Condition), Shape: shapeDecision})
+		clusterID := childCluster()
+		for _, c := range step.Switch.Cases {
+			tails = append(tails, g.addSteps(clusterID, []tail{{node: id, label: c.Name}}, c.Steps)...)
+		}
+		tails = append(tails, g.addSteps(clusterID, []tail{{node: id, label: "default"}}, step.Switch.Default)...)
+	case step.While != nil:
+		g.addNode(node{ID: id, Cluster: parentCluster, Label: stepLabel(step, "while", step.While.Condition), Shape: shapeLoop})
+		if len(step.While.Steps) > 0 {
+			g.connect(g.addSteps(childCluster(), []tail{{node: id, label: "true"}}, step.While.Steps), id)
+		}
+		tails = []tail{{node: id, label: "false"}}
+	case step.ForEach != nil:
+		g.addNode(node{ID: id, Cluster: parentCluster, Label: stepLabel(step, "for each", step.ForEach.Items), Shape: shapeLoop})
+		if len(step.ForEach.Steps) > 0 {
+			g.connect(g.addSteps(childCluster(), []tail{{node: id, label: "each"}}, step.ForEach.Steps), id)
+		}
+		tails = []tail{{node: id, label: "done"}}
+	case step.Parallel != nil:
+		g.addNode(node{ID: id, Cluster: parentCluster, Label: stepLabel(step, "parallel", ""), Shape: shapeFork})
+		clusterID := childCluster()
+		join := g.id("")
+		for i, branch := range step.Parallel.Branches {
+			g.connect(g.addSteps(clusterID, []tail{{node: id, label: fmt.Sprintf("branch %d", i+1)}}, branch.Steps), join)
+		}
+		g.addNode(node{ID: join, Cluster: parentCluster, Label: "join", Shape: shapeFork})
+		tails = []tail{{node: join}}
+	case step.Approval != nil:
+		g.addNode(node{ID: id, Cluster: parentCluster, Label: stepLabel(step, "approval", step.Approval.Message), Shape: shapeApproval})
+		tails = []tail{{node: id, label: "approved"}}
+	case step.Wait != nil:
+		g.addNode(node{ID: id, Cluster: parentCluster, Label: stepLabel(step, "wait", waitText(*step.Wait))})
+		tails = []tail{{node: id}}
+	case step.WaitForEvent != nil:
+		g.addNode(node{ID: id, Cluster: parentCluster, Label: stepLabel(step, "wait for event", step.WaitForEvent.Timeout)})
+		tails = []tail{{node: id, label: "received"}}
+	case step.Tool != nil:
+		g.addNode(node{ID: id, Cluster: parentCluster, Label: stepLabel(step, "tool", step.Tool.Name)})
+		tails = []tail{{node: id}}
+	case step.Template != nil:
+		g.addNode(node{ID: id, Cluster: parentCluster, Label: stepLabel(step, "template", step.Template.Name)})
+		tails = []tail{{node: id}}
+	default:
+		g.addNode(node{ID: id, Cluster: parentCluster, Label: stepLabel(step, "", step.Step)})
+		tails = []tail{{node: id}}
+	}
+
+	if step.OnError != nil {
+		clusterID := g.id("")
+		g.addCluster(cluster{ID: clusterID, Parent: parentCluster, Label: "onError: " + step.ID})
+		handlerTails := g.addSteps(clusterID, []tail{{node: id, label: "error", dashed: true}}, step.OnError.Steps)
+		if step.OnError.Recover {
+			tails = append(tails, handlerTails...)
+		}
+	}
+
+	return tails
+}
+
+func stepLabel(step types.Step, kind, text string) string {
+	label := step.ID
+	if kind != "" {
+		label += "\n" + kind
+		if text != "" {
+			label += ": "
+		}
+	} else if text != "" {
+		label += "\n"
+	}
+	return label + summary(text)
+}
+
+func waitText(wait types.Wait) string {
+	if wait.Duration != "" {
+		return wait.Duration
+	}
+	text := "until " + wait.Until
+	if wait.Timezone != "" {
+		text += " " + wait.Timezone
+	}
+	return text
+}
@@ -0,0 +1,232 @@
+package workflowparams
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+
+	"github.com/obot-platform/obot/apiclient/types"
+)
+
+const (
+	TypeString  = "string"
+	TypeNumber  = "number"
+	TypeInteger = "integer"
+	TypeBoolean = "boolean"
+	TypeObject  = "object"
+	TypeArray   = "array"
+)
+
+var validTypes = []string{TypeString, TypeNumber, TypeInteger, TypeBoolean, TypeObject, TypeArray}
+
+// ValidateDefinitions checks that the params of a workflow are well-formed, so that bad definitions are rejected when
+// the workflow is saved instead of when it is executed.
+func ValidateDefinitions(params []types.WorkflowParam) error {
+	seen := make(map[string]struct{}, len(params))
+	for _, param := range params {
+		if param.Name == "" {
+			return fmt.Errorf("param name is required")
+		}
+		if _, ok := seen[param.Name]; ok {
+			return fmt.Errorf("duplicate param %q", param.Name)
+		}
+		seen[param.Name] = struct{}{}
+
+		if param.Type != "" && !slices.Contains(validTypes, param.Type) {
+			return fmt.Errorf("param %q has invalid type %q, must be one of %s", param.Name, param.Type, strings.Join(validTypes, ", "))
+		}
+
+		for _, value := range param.Enum {
+			if _, err := parseValue(param, value); err != nil {
+				return fmt.Errorf("param %q has invalid enum value %q: %w", param.Name, value, err)
+			}
+		}
+
+		if param.Default != "" {
+			value, err := parseValue(param, param.Default)
+			if err != nil {
+				return fmt.Errorf("param %q has invalid default %q: %w", param.Name, param.Default, err)
+			}
+			if err := checkEnum(param, value); err != nil {
+				return fmt.Errorf("param %q has invalid default: %w", param.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Validate checks the input of a workflow execution against the params of the workflow and returns the input with
+// the defaults applied. If no default applies, the input is returned as it was given, so that the fields keep their
+// order. Otherwise the object is encoded again with the defaults set for the params that are missing or null, numbers
+// keep their precision. If the workflow has no params, the input is returned as is.
+func Validate(params []types.WorkflowParam, input string) (string, error) {
+	if len(params) == 0 {
+		return input, nil
+	}
+
+	values := map[string]any{}
+	if strings.TrimSpace(input) != "" {
+		if err := decode(input, &values); err != nil || values == nil {
+			return "", fmt.Errorf("input must be a JSON object with the params of the workflow")
+		}
+	}
+
+	var (
+		problems []string
+		defaults = map[string]any{}
+	)
+	for _, param := range params {
+		value, ok := values[param.Name]
+		if !ok || value == nil {
+			if param.Default != "" {
+				defaultValue, err := parseValue(param, param.Default)
+				if err != nil {
+					return "", fmt.Errorf("param %q has invalid default %q: %w", param.Name, param.Default, err)
+				}
+				defaults[param.Name] = defaultValue
+			} else if param.Required {
+				problems = append(problems, fmt.Sprintf("param %q is required", param.Name))
+			}
+			continue
+		}
+
+		if err := checkType(param, value); err != nil {
+			problems = append(problems, fmt.Sprintf("param %q %v", param.Name, err))
+		} else if err := checkEnum(param, value); err != nil {
+			problems = append(problems, fmt.Sprintf("param %q %v", param.Name, err))
+		}
+	}
+
+	if len(problems) > 0 {
+		return "", fmt.Errorf("invalid input: %s", strings.Join(problems, "; "))
+	}
+
+	if len(defaults) == 0 {
+		return input, nil
+	}
+	for name, value := range defaults {
+		values[name] = value
+	}
+	data, err := json.Marshal(values)
+	return string(data), err
+}
+
+// Values returns the input of a workflow execution as a JSON object that has a field for every param, so that
+// references to optional params that weren't given resolve to an empty string.
+func Values(params []types.WorkflowParam, input string) (string, error) {
+	values := map[string]any{}
+	if strings.TrimSpace(input) != "" {
+		if err := decode(input, &values); err != nil || values == nil {
+			values = map[string]any{}
+		}
+	}
+
+	for _, param := range params {
+		if _, ok := values[param.Name]; !ok {
+			values[param.Name] = ""
+		}
+	}
+
+	data, err := json.Marshal(values)
+	return string(data), err
+}
+
+// decode decodes JSON with numbers kept as json.Number, so that large integers don't lose precision.
+func decode(data string, v any) error {
+	decoder := json.NewDecoder(strings.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+	if decoder.More() {
+		return fmt.Errorf("unexpected data after JSON value")
+	}
+	return nil
+}
+
+func parseValue(param types.WorkflowParam, value string) (any, error) {
+	if param.Type == "" || param.Type == TypeString {
+		return value, nil
+	}
+
+	var result any
+	if err := decode(value, &result); err != nil {
+		return nil, fmt.Errorf("not a valid %s", param.Type)
+	}
+	return result, checkType(param, result)
+}
+
+func checkType(param types.WorkflowParam, value any) error {
+	var ok bool
+	switch param.Type {
+	case "", TypeString:
+		_, ok = value.(string)
+	case TypeNumber:
+		_, ok = number(value)
+	case TypeInteger:
+		var f *big.Float
+		f, ok = number(value)
+		ok = ok && f.IsInt()
+	case TypeBoolean:
+		_, ok = value.(bool)
+	case TypeObject:
+		_, ok = value.(map[string]any)
+	case TypeArray:
+		_, ok = value.([]any)
+	default:
+		return fmt.Errorf("has invalid type %q", param.Type)
+	}
+	if !ok {
+		typ := param.Type
+		if typ == "" {
+			typ = TypeString
+		}
+		return fmt.Errorf("must be of type %s", typ)
+	}
+	return nil
+}
+
+func number(value any) (*big.Float, bool) {
+	n, ok := value.(json.Number)
+	if !ok {
+		return nil, false
+	}
+	f, _, err := big.ParseFloat(string(n), 10, 256, big.ToNearestEven)
+	return f, err == nil
+}
+
+func checkEnum(param types.WorkflowParam, value any) error {
+	if len(param.Enum) == 0 {
+		return nil
+	}
+
+	for _, allowed := range param.Enum {
+		allowedValue, err := parseValue(param, allowed)
+		if err != nil {
+			continue
+		}
+		if equal(allowedValue, value) {
+			return nil
+		}
+	}
+	return fmt.Errorf("must be one of %s", strings.Join(param.Enum, ", "))
+}
+
+func equal(a, b any) bool {
+	if aNumber, ok := number(a); ok {
+		bNumber, ok := number(b)
+		return ok && aNumber.Cmp(bNumber) == 0
+	}
+
+	aData, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	bData, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return string(aData) == string(bData)
+}
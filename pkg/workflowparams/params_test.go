@@ -0,0 +1,116 @@
+package workflowparams
+
+import (
+	"testing"
+
+	"github.com/obot-platform/obot/apiclient/types"
+)
+
+func TestValidate(t *testing.T) {
+	params := []types.WorkflowParam{
+		{Name: "id", Type: TypeInteger, Required: true},
+		{Name: "ratio", Type: TypeNumber},
+		{Name: "mode", Enum: []string{"fast", "slow"}, Default: "fast"},
+		{Name: "count", Type: TypeInteger, Enum: []string{"1", "2"}},
+	}
+
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "input is returned unchanged",
+			input: `{"mode": "slow",  "id": 9007199254740993, "extra": {"b": 1, "a": 2}}`,
+			want:  `{"mode": "slow",  "id": 9007199254740993, "extra": {"b": 1, "a": 2}}`,
+		},
+		{
+			name:  "defaults keep the precision of numbers",
+			input: `{"id": 12345678901234567890}`,
+			want:  `{"id":12345678901234567890,"mode":"fast"}`,
+		},
+		{
+			name:  "defaults of a null param",
+			input: `{"id": 1, "mode": null}`,
+			want:  `{"id":1,"mode":"fast"}`,
+		},
+		{
+			name:    "empty input misses the required param",
+			input:   "",
+			wantErr: true,
+		},
+		{
+			name:  "integer written as a float",
+			input: `{"id": 1.0, "ratio": 0.5}`,
+			want:  `{"id":1.0,"mode":"fast","ratio":0.5}`,
+		},
+		{
+			name:    "fraction is not an integer",
+			input:   `{"id": 1.5}`,
+			wantErr: true,
+		},
+		{
+			name:  "enum numbers compare by value",
+			input: `{"id": 1, "count": 2.0, "mode": "slow"}`,
+			want:  `{"id": 1, "count": 2.0, "mode": "slow"}`,
+		},
+		{
+			name:    "value outside of the enum",
+			input:   `{"id": 1, "mode": "medium"}`,
+			wantErr: true,
+		},
+		{
+			name:    "string for a number",
+			input:   `{"id": "1"}`,
+			wantErr: true,
+		},
+		{
+			name:    "not an object",
+			input:   `[1]`,
+			wantErr: true,
+		},
+		{
+			name:    "trailing data",
+			input:   `{"id": 1} {"id": 2}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Validate(params, tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Validate(%q) = %q, want error", tt.input, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Validate(%q) returned error: %v", tt.input, err)
+			}
+			if got != tt.want {
+				t.Errorf("Validate(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateWithoutParams(t *testing.T) {
+	input := `not even JSON`
+	if got, err := Validate(nil, input); err != nil || got != input {
+		t.Errorf("Validate(nil, %q) = %q, %v, want the input unchanged", input, got, err)
+	}
+}
+
+func TestValues(t *testing.T) {
+	params := []types.WorkflowParam{{Name: "id", Type: TypeInteger}, {Name: "name"}}
+
+	got, err := Values(params, `{"id": 9007199254740993}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"id":9007199254740993,"name":""}`; got != want {
+		t.Errorf("Values() = %s, want %s", got, want)
+	}
+}
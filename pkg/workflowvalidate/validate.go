@@ -0,0 +1,445 @@
+package workflowvalidate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/obot-platform/nah/pkg/router"
+	"github.com/obot-platform/obot/apiclient/types"
+	"github.com/obot-platform/obot/pkg/controller/handlers/retention"
+	"github.com/obot-platform/obot/pkg/controller/handlers/workflowexecution"
+	"github.com/obot-platform/obot/pkg/controller/handlers/workflowstep"
+	ktime "github.com/obot-platform/obot/pkg/gateway/time"
+	"github.com/obot-platform/obot/pkg/invoke"
+	"github.com/obot-platform/obot/pkg/render"
+	v1 "github.com/obot-platform/obot/pkg/storage/apis/obot.obot.ai/v1"
+	"github.com/obot-platform/obot/pkg/system"
+	"github.com/obot-platform/obot/pkg/workflowparams"
+	"github.com/xeipuuv/gojsonschema"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Problem is a mistake in a workflow manifest and the path of the field that has it, like steps[0].while.steps[1].
+type Problem struct {
+	Path    string
+	Message string
+}
+
+func (p Problem) Error() string {
+	if p.Path == "" {
+		return p.Message
+	}
+	return p.Path + ": " + p.Message
+}
+
+// Error returns the problems as a single error, or nil if there are none.
+func Error(problems []Problem) error {
+	errs := make([]error, 0, len(problems))
+	for _, problem := range problems {
+		errs = append(errs, problem)
+	}
+	return errors.Join(errs...)
+}
+
+// Manifest checks a workflow manifest without looking up what it references. Step IDs must be unique, every step must
+// do exactly one thing and have what it needs to do it, and durations, schemas and ${...} references must parse.
+func Manifest(manifest types.WorkflowManifest) []Problem {
+	c := newChecker(manifest, nil)
+	c.manifest(manifest)
+	return c.problems
+}
+
+// Validate checks a workflow manifest like Manifest does, and that the tools, agents, workflows and step templates it
+// references exist in the namespace.
+func Validate(ctx context.Context, client kclient.Client, namespace string, manifest types.WorkflowManifest) ([]Problem, error) {
+	c := newChecker(manifest, &resolver{
+		ctx:       ctx,
+		client:    client,
+		namespace: namespace,
+	})
+	c.manifest(manifest)
+	if c.resolver.err != nil {
+		return nil, c.resolver.err
+	}
+	return c.problems, nil
+}
+
+// RunUntilStep checks the step that a rerun of a workflow execution runs until, which must be a step of the manifest.
+// The suffix that the controller adds to the IDs of the steps it creates, like {index=1}, is ignored, and * reruns
+// every step.
+func RunUntilStep(manifest types.WorkflowManifest, stepID string) error {
+	if stepID == "" || stepID == "*" {
+		return nil
+	}
+
+	c := newChecker(manifest, nil)
+	c.manifest(manifest)
+	if id, _, _ := strings.Cut(stepID, "{"); c.ids[id] == "" {
+		return fmt.Errorf("step %s not found", stepID)
+	}
+	return nil
+}
+
+type checker struct {
+	problems []Problem
+	ids      map[string]string
+	refs     *invoke.ReferenceChecker
+	resolver *resolver
+}
+
+func newChecker(manifest types.WorkflowManifest, resolver *resolver) *checker {
+	return &checker{
+		ids:      map[string]string{},
+		refs:     invoke.NewReferenceChecker(manifest),
+		resolver: resolver,
+	}
+}
+
+func (c *checker) add(path, format string, args ...any) {
+	c.problems = append(c.problems, Problem{
+		Path:    path,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (c *checker) manifest(manifest types.WorkflowManifest) {
+	if err := workflowparams.ValidateDefinitions(manifest.InputParams); err != nil {
+		c.add("inputParams", "%v", err)
+	}
+	if err := workflowexecution.ValidateConcurrency(manifest.Concurrency); err != nil {
+		c.add("concurrency", "%v", err)
+	}
+	if err := workflowexecution.ValidateBudget(manifest.Budget); err != nil {
+		c.add("budget", "%v", err)
+	}
+	if err := retention.Validate(manifest.Retention); err != nil {
+		c.add("retention", "%v", err)
+	}
+
+	c.references("", manifest.Tools, manifest.Agents, manifest.Workflows)
+	c.schema("outputSchema", manifest.OutputSchema)
+	if manifest.OutputSchema != nil && len(manifest.OutputSchema.Schema) > 0 && manifest.Output == "" {
+		c.add("outputSchema", "requires output, set the outputSchema of the last step instead")
+	}
+	c.text("output", manifest.Output)
+
+	c.steps("steps", manifest.Steps)
+	if manifest.OnError != nil {
+		c.steps("onError.steps", manifest.OnError.Steps)
+	}
+}
+
+func (c *checker) steps(path string, steps []types.Step) {
+	for i, step := range steps {
+		c.step(fmt.Sprintf("%s[%d]", path, i), step)
+	}
+}
+
+func (c *checker) step(path string, step types.Step) {
+	c.id(path, step.ID)
+
+	if kinds := stepKinds(step); len(kinds) > 1 {
+		c.add(path, "only one of %s can be set", strings.Join(kinds, ", "))
+	} else if len(kinds) == 0 && strings.TrimSpace(step.Step) == "" {
+		c.add(path, "step has nothing to do, set step or one of if, while, forEach, parallel, switch, approval, wait, waitForEvent, tool or template")
+	}
+
+	c.text(path+".step", step.Step)
+	c.references(path, step.Tools, step.Agents, step.Workflows)
+	c.schema(path+".outputSchema", step.OutputSchema)
+
+	if err := workflowstep.ValidateRetry(step.Retry); err != nil {
+		c.add(path+".retry", "%v", err)
+	}
+
+	if step.If != nil {
+		c.required(path+".if.condition", step.If.Condition, "condition")
+		if len(step.If.Steps) == 0 && len(step.If.Else) == 0 {
+			c.add(path+".if", "if has no steps")
+		}
+		c.steps(path+".if.steps", step.If.Steps)
+		c.steps(path+".if.else", step.If.Else)
+	}
+	if step.While != nil {
+		c.required(path+".while.condition", step.While.Condition, "condition")
+		if step.While.MaxLoops < 0 {
+			c.add(path+".while.maxLoops", "maxLoops must not be negative")
+		}
+		if len(step.While.Steps) == 0 {
+			c.add(path+".while", "while has no steps")
+		}
+		c.steps(path+".while.steps", step.While.Steps)
+	}
+	if step.ForEach != nil {
+		c.required(path+".forEach.items", step.ForEach.Items, "items")
+		if step.ForEach.Parallelism < 0 {
+			c.add(path+".forEach.parallelism", "parallelism must not be negative")
+		}
+		if len(step.ForEach.Steps) == 0 {
+			c.add(path+".forEach", "forEach has no steps")
+		}
+		c.steps(path+".forEach.steps", step.ForEach.Steps)
+	}
+	if step.Parallel != nil {
+		if len(step.Parallel.Branches) == 0 {
+			c.add(path+".parallel", "parallel has no branches")
+		}
+		for i, branch := range step.Parallel.Branches {
+			branchPath := fmt.Sprintf("%s.parallel.branches[%d]", path, i)
+			if len(branch.Steps) == 0 {
+				c.add(branchPath, "branch has no steps")
+			}
+			c.steps(branchPath+".steps", branch.Steps)
+		}
+	}
+	if step.Switch != nil {
+		c.required(path+".switch.condition", step.Switch.Condition, "condition")
+		if len(step.Switch.Cases) == 0 {
+			c.add(path+".switch", "switch has no cases")
+		}
+		names := map[string]struct{}{}
+		for i, switchCase := range step.Switch.Cases {
+			casePath := fmt.Sprintf("%s.switch.cases[%d]", path, i)
+			if switchCase.Name == "" {
+				c.add(casePath+".name", "name is required")
+			} else if _, ok := names[switchCase.Name]; ok {
+				c.add(casePath+".name", "duplicate case %q", switchCase.Name)
+			}
+			names[switchCase.Name] = struct{}{}
+			c.steps(casePath+".steps", switchCase.Steps)
+		}
+		c.steps(path+".switch.default", step.Switch.Default)
+	}
+	if step.Approval != nil {
+		c.text(path+".approval.message", step.Approval.Message)
+		if step.Approval.Timeout != "" {
+			if d, err := ktime.ParseDuration(step.Approval.Timeout); err != nil || d <= 0 {
+				c.add(path+".approval.timeout", "invalid timeout %q, must be a positive duration like 1d", step.Approval.Timeout)
+			}
+		}
+	}
+	if step.Wait != nil {
+		// Waits that reference the input can only be checked when the workflow runs.
+		if !slices.ContainsFunc([]string{step.Wait.Duration, step.Wait.Until, step.Wait.Timezone}, hasReference) {
+			if _, err := workflowstep.WaitUntil(&v1.WorkflowExecution{}, *step.Wait, time.Now()); err != nil {
+				c.add(path+".wait", "%v", err)
+			}
+		}
+	}
+	if step.WaitForEvent != nil {
+		if timeout := step.WaitForEvent.Timeout; timeout != "" && !hasReference(timeout) {
+			if d, err := ktime.ParseDuration(timeout); err != nil || d <= 0 {
+				c.add(path+".waitForEvent.timeout", "invalid timeout %q, must be a positive duration like 1h", timeout)
+			}
+		}
+		if (step.WaitForEvent.ValidationHeader != "") != (step.WaitForEvent.Secret != "") {
+			c.add(path+".waitForEvent", "secret and validationHeader must be set together")
+		}
+	}
+	if step.Tool != nil {
+		c.required(path+".tool.name", step.Tool.Name, "name")
+		for _, k := range slices.Sorted(maps.Keys(step.Tool.Args)) {
+			c.text(path+".tool.args."+k, step.Tool.Args[k])
+		}
+		if step.Tool.Name != "" {
+			c.resolver.tool(c, path+".tool.name", step.Tool.Name)
+		}
+	}
+	if step.Template != nil {
+		c.required(path+".template.name", step.Template.Name, "name")
+		for _, k := range slices.Sorted(maps.Keys(step.Template.Args)) {
+			c.text(path+".template.args."+k, step.Template.Args[k])
+		}
+		if step.Template.Name != "" {
+			c.resolver.template(c, path+".template", *step.Template)
+		}
+	}
+	if step.OnError != nil {
+		c.steps(path+".onError.steps", step.OnError.Steps)
+	}
+}
+
+func (c *checker) id(path, id string) {
+	if id == "" {
+		// IDs are generated for steps that don't have one when the workflow is saved.
+		return
+	}
+	if strings.ContainsAny(id, "{}") {
+		c.add(path+".id", "step ID %q must not contain { or }", id)
+	}
+	if other, ok := c.ids[id]; ok {
+		c.add(path+".id", "duplicate step ID %q, also used by %s", id, other)
+		return
+	}
+	c.ids[id] = path
+}
+
+func (c *checker) required(path, value, name string) {
+	if strings.TrimSpace(value) == "" {
+		c.add(path, "%s is required", name)
+		return
+	}
+	c.text(path, value)
+}
+
+func (c *checker) text(path, text string) {
+	for _, err := range c.refs.Check(text) {
+		c.add(path, "%v", err)
+	}
+}
+
+func (c *checker) schema(path string, schema *types.OutputSchema) {
+	if schema == nil || len(schema.Schema) == 0 {
+		return
+	}
+	if _, err := gojsonschema.NewSchema(gojsonschema.NewBytesLoader(schema.Schema)); err != nil {
+		c.add(path, "invalid JSON schema: %v", err)
+	}
+}
+
+func (c *checker) references(path string, tools, agents, workflows []string) {
+	if path != "" {
+		path += "."
+	}
+	for i, tool := range tools {
+		c.resolver.tool(c, fmt.Sprintf("%stools[%d]", path, i), tool)
+	}
+	for i, agent := range agents {
+		c.resolver.agent(c, fmt.Sprintf("%sagents[%d]", path, i), agent)
+	}
+	for i, workflow := range workflows {
+		c.resolver.workflow(c, fmt.Sprintf("%sworkflows[%d]", path, i), workflow)
+	}
+}
+
+func stepKinds(step types.Step) (kinds []string) {
+	for _, kind := range []struct {
+		name string
+		set  bool
+	}{
+		{"if", step.If != nil},
+		{"while", step.While != nil},
+		{"forEach", step.ForEach != nil},
+		{"parallel", step.Parallel != nil},
+		{"switch", step.Switch != nil},
+		{"approval", step.Approval != nil},
+		{"wait", step.Wait != nil},
+		{"waitForEvent", step.WaitForEvent != nil},
+		{"tool", step.Tool != nil},
+		{"template", step.Template != nil},
+	} {
+		if kind.set {
+			kinds = append(kinds, kind.name)
+		}
+	}
+	return kinds
+}
+
+func hasReference(text string) bool {
+	return strings.Contains(text, "${")
+}
+
+// resolver looks up what a workflow references. A nil resolver skips the lookups. The first error that isn't a
+// problem with the workflow is kept and stops further lookups.
+type resolver struct {
+	ctx       context.Context
+	client    kclient.Client
+	namespace string
+	err       error
+
+	agents    map[string]v1.Agent
+	workflows map[string]v1.Workflow
+}
+
+func (r *resolver) tool(c *checker, path, name string) {
+	if r == nil || r.err != nil || render.IsExternalTool(name) {
+		return
+	}
+
+	if system.IsToolID(name) {
+		var tool v1.Tool
+		if err := r.client.Get(r.ctx, router.Key(r.namespace, name), &tool); apierrors.IsNotFound(err) {
+			c.add(path, "unknown tool %q", name)
+		} else if err != nil {
+			r.err = err
+		}
+		return
+	}
+
+	ref, ok := r.toolReference(c, path, "tool", name)
+	if ok && ref.Spec.Type != types.ToolReferenceTypeTool {
+		c.add(path, "%q is a %s, not a tool", name, ref.Spec.Type)
+	}
+}
+
+func (r *resolver) template(c *checker, path string, template types.Template) {
+	if r == nil || r.err != nil || render.IsExternalTool(template.Name) {
+		return
+	}
+
+	ref, ok := r.toolReference(c, path+".name", "step template", template.Name)
+	if !ok {
+		return
+	}
+	if ref.Spec.Type != types.ToolReferenceTypeStepTemplate {
+		c.add(path+".name", "%q is a %s, not a step template", template.Name, ref.Spec.Type)
+		return
+	}
+	if ref.Status.Tool == nil {
+		// The template hasn't been loaded yet, so its params are unknown.
+		return
+	}
+
+	for _, arg := range slices.Sorted(maps.Keys(template.Args)) {
+		if _, ok := ref.Status.Tool.Params[arg]; !ok {
+			c.add(path+".args."+arg, "unknown argument %q of step template %s, expected one of: %s", arg, template.Name,
+				strings.Join(slices.Sorted(maps.Keys(ref.Status.Tool.Params)), ", "))
+		}
+	}
+}
+
+func (r *resolver) toolReference(c *checker, path, kind, name string) (v1.ToolReference, bool) {
+	var ref v1.ToolReference
+	if err := r.client.Get(r.ctx, router.Key(r.namespace, name), &ref); apierrors.IsNotFound(err) {
+		c.add(path, "unknown %s %q", kind, name)
+		return ref, false
+	} else if err != nil {
+		r.err = err
+		return ref, false
+	}
+	return ref, true
+}
+
+func (r *resolver) agent(c *checker, path, name string) {
+	if r == nil || r.err != nil {
+		return
+	}
+	if r.agents == nil {
+		if r.agents, r.err = render.AgentByName(r.ctx, r.client, r.namespace); r.err != nil {
+			return
+		}
+	}
+	if _, ok := r.agents[name]; !ok {
+		c.add(path, "unknown agent %q", name)
+	}
+}
+
+func (r *resolver) workflow(c *checker, path, name string) {
+	if r == nil || r.err != nil {
+		return
+	}
+	if r.workflows == nil {
+		if r.workflows, r.err = render.WorkflowByName(r.ctx, r.client, r.namespace); r.err != nil {
+			return
+		}
+	}
+	if _, ok := r.workflows[name]; !ok {
+		c.add(path, "unknown workflow %q", name)
+	}
+}